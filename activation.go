@@ -0,0 +1,268 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// Activation is function applied element-wise to weighted input of a layer
+type Activation interface {
+	// Apply returns activation of weighted input
+	Apply(matrices.Matrix) matrices.Matrix
+	// Prime returns derivative of activation at weighted input
+	Prime(matrices.Matrix) matrices.Matrix
+}
+
+// Sigmoid is logistic activation, default activation of all layers
+type Sigmoid struct{}
+
+// Apply implements Activation interface
+func (Sigmoid) Apply(z matrices.Matrix) matrices.Matrix {
+	return z.Sigmoid()
+}
+
+// Prime implements Activation interface
+func (Sigmoid) Prime(z matrices.Matrix) matrices.Matrix {
+	return z.SigmoidPrime()
+}
+
+// PrimeFromActivation returns derivative of sigmoid from its already computed output
+func (Sigmoid) PrimeFromActivation(a matrices.Matrix) matrices.Matrix {
+	return matrices.SigmoidPrimeFromActivation(a)
+}
+
+// ReLU is rectified linear activation
+type ReLU struct{}
+
+// Apply implements Activation interface
+func (ReLU) Apply(z matrices.Matrix) matrices.Matrix {
+	return z.ReLU()
+}
+
+// Prime implements Activation interface
+func (ReLU) Prime(z matrices.Matrix) matrices.Matrix {
+	return z.ReLUPrime()
+}
+
+// LeakyReLU is rectified linear activation which passes negative inputs multiplied by Alpha,
+// so that neurons with negative input still learn. Zero Alpha means 0.01
+type LeakyReLU struct {
+	Alpha float64
+}
+
+func (activation LeakyReLU) alpha() float64 {
+	if activation.Alpha == 0 {
+		return 0.01
+	}
+	return activation.Alpha
+}
+
+// Apply implements Activation interface
+func (activation LeakyReLU) Apply(z matrices.Matrix) matrices.Matrix {
+	return z.LeakyReLU(activation.alpha())
+}
+
+// Prime implements Activation interface
+func (activation LeakyReLU) Prime(z matrices.Matrix) matrices.Matrix {
+	return z.LeakyReLUPrime(activation.alpha())
+}
+
+// ELU is exponential linear activation, x for positive x and Alpha*(exp(x)-1) otherwise. Zero Alpha means 1
+type ELU struct {
+	Alpha float64
+}
+
+func (activation ELU) alpha() float64 {
+	if activation.Alpha == 0 {
+		return 1
+	}
+	return activation.Alpha
+}
+
+// Apply implements Activation interface
+func (activation ELU) Apply(z matrices.Matrix) matrices.Matrix {
+	return z.ELU(activation.alpha())
+}
+
+// Prime implements Activation interface
+func (activation ELU) Prime(z matrices.Matrix) matrices.Matrix {
+	return z.ELUPrime(activation.alpha())
+}
+
+// PrimeFromActivation returns derivative of ELU from its already computed output, which is output plus Alpha
+// for non-positive input
+func (activation ELU) PrimeFromActivation(a matrices.Matrix) matrices.Matrix {
+	alpha := activation.alpha()
+	return a.Apply(func(f float64) float64 {
+		if f > 0 {
+			return 1
+		}
+		return f + alpha
+	})
+}
+
+// Tanh is hyperbolic tangent activation
+type Tanh struct{}
+
+// Apply implements Activation interface
+func (Tanh) Apply(z matrices.Matrix) matrices.Matrix {
+	return z.Tanh()
+}
+
+// Prime implements Activation interface
+func (Tanh) Prime(z matrices.Matrix) matrices.Matrix {
+	return z.TanhPrime()
+}
+
+// PrimeFromActivation returns derivative of hyperbolic tangent from its already computed output
+func (Tanh) PrimeFromActivation(a matrices.Matrix) matrices.Matrix {
+	return a.Apply(matrices.Square).Apply(matrices.OneMinus)
+}
+
+// Linear is identity activation, usual output activation for regression
+type Linear struct{}
+
+// Apply implements Activation interface
+func (Linear) Apply(z matrices.Matrix) matrices.Matrix {
+	return z.Copy()
+}
+
+// Prime implements Activation interface
+func (Linear) Prime(z matrices.Matrix) matrices.Matrix {
+	return matrices.Ones(z.Rows(), z.Cols())
+}
+
+// Softmax normalizes outputs of every row into probability distribution, it can only be used by output layer
+// of mutually exclusive classes and networks created with it get CategoricalCrossEntropy cost
+type Softmax struct{}
+
+// Apply implements Activation interface
+func (Softmax) Apply(z matrices.Matrix) matrices.Matrix {
+	return z.Softmax()
+}
+
+// Prime implements Activation interface, it returns only diagonal of Jacobian of softmax, s * (1 - s).
+// It is not used by CategoricalCrossEntropy cost whose output delta is output minus target
+func (Softmax) Prime(z matrices.Matrix) matrices.Matrix {
+	return matrices.SigmoidPrimeFromActivation(z.Softmax())
+}
+
+// PrimeFromActivation returns diagonal of Jacobian of softmax from its already computed output
+func (Softmax) PrimeFromActivation(a matrices.Matrix) matrices.Matrix {
+	return matrices.SigmoidPrimeFromActivation(a)
+}
+
+// isSoftmax reports whether activation is Softmax
+func isSoftmax(activation Activation) bool {
+	switch activation.(type) {
+	case Softmax, *Softmax:
+		return true
+	}
+	return false
+}
+
+// isProbabilistic reports whether outputs of activation lie in [0,1] and can be read as probabilities
+func isProbabilistic(activation Activation) bool {
+	switch activation.(type) {
+	case Sigmoid, *Sigmoid:
+		return true
+	}
+	return isSoftmax(activation)
+}
+
+// checkSoftmax returns error when Softmax is activation of hidden layer, its derivative is only diagonal
+// of Jacobian, which is exact only together with CategoricalCrossEntropy cost of output layer
+func checkSoftmax(activations []Activation) error {
+	for i := 0; i < len(activations)-1; i++ {
+		if isSoftmax(activations[i]) {
+			return errors.New("nn: softmax can only be activation of output layer")
+		}
+	}
+	return nil
+}
+
+// activationPrimer is implemented by activations whose derivative can be computed from their output,
+// backprop uses it to skip evaluating activation again
+type activationPrimer interface {
+	PrimeFromActivation(matrices.Matrix) matrices.Matrix
+}
+
+// prime returns derivative of activation of layer transition i at weighted input z with output a
+func (network NN) prime(i int, z, a matrices.Matrix) matrices.Matrix {
+	activation := network.activation(i)
+	if primer, ok := activation.(activationPrimer); ok {
+		return primer.PrimeFromActivation(a)
+	}
+	return activation.Prime(z)
+}
+
+// activation returns activation of layer transition i
+func (network NN) activation(i int) Activation {
+	if i < len(network.activations) && network.activations[i] != nil {
+		return network.activations[i]
+	}
+	return Sigmoid{}
+}
+
+func activationName(activation Activation) (string, error) {
+	switch activation := activation.(type) {
+	case Sigmoid, *Sigmoid:
+		return "sigmoid", nil
+	case ReLU, *ReLU:
+		return "relu", nil
+	case Tanh, *Tanh:
+		return "tanh", nil
+	case Linear, *Linear:
+		return "linear", nil
+	case Softmax, *Softmax:
+		return "softmax", nil
+	case LeakyReLU:
+		return "leaky-relu:" + strconv.FormatFloat(activation.alpha(), 'g', -1, 64), nil
+	case *LeakyReLU:
+		return activationName(*activation)
+	case ELU:
+		return "elu:" + strconv.FormatFloat(activation.alpha(), 'g', -1, 64), nil
+	case *ELU:
+		return activationName(*activation)
+	}
+	return "", fmt.Errorf("nn: cannot serialize activation of type %T", activation)
+}
+
+// activationByName returns activation of given name, parametrized activations may be followed by colon
+// and their parameter, e.g. "leaky-relu:0.2"
+func activationByName(name string) (Activation, error) {
+	if base, parameter, ok := strings.Cut(name, ":"); ok {
+		alpha, err := strconv.ParseFloat(parameter, 64)
+		if err != nil {
+			return nil, fmt.Errorf("nn: invalid parameter of activation %q: %v", name, err)
+		}
+		switch base {
+		case "leaky-relu":
+			return LeakyReLU{alpha}, nil
+		case "elu":
+			return ELU{alpha}, nil
+		}
+		return nil, fmt.Errorf("nn: unknown activation %q", name)
+	}
+	switch name {
+	case "sigmoid":
+		return Sigmoid{}, nil
+	case "relu":
+		return ReLU{}, nil
+	case "tanh":
+		return Tanh{}, nil
+	case "linear":
+		return Linear{}, nil
+	case "softmax":
+		return Softmax{}, nil
+	case "leaky-relu":
+		return LeakyReLU{}, nil
+	case "elu":
+		return ELU{}, nil
+	}
+	return nil, fmt.Errorf("nn: unknown activation %q", name)
+}
@@ -0,0 +1,62 @@
+package nn
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSoftmaxOutputUsesCategoricalCrossEntropy(t *testing.T) {
+	network := NewNN([]int{2, 4, 3}, WithActivation(Sigmoid{}, Softmax{}))
+	if network.cost != CategoricalCrossEntropy {
+		t.Errorf("cost = %v, want %v", network.cost, CategoricalCrossEntropy)
+	}
+}
+
+func TestSoftmaxHiddenLayerPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("single Softmax activation of network with hidden layer did not panic")
+		}
+	}()
+	NewNN([]int{2, 4, 3}, WithActivation(Softmax{}))
+}
+
+func TestTrainRejectsSoftmaxWithBinaryCrossEntropy(t *testing.T) {
+	network := NewNN([]int{2, 4, 3}, WithActivation(Sigmoid{}, Softmax{}))
+	network.SetCostFunction(CrossEntropy)
+	_, err := network.TrainWithConfig(blobs(30, 3, 1), TrainConfig{Epochs: 1})
+	if err == nil || !strings.Contains(err.Error(), "CategoricalCrossEntropy") {
+		t.Errorf("err = %v, want error requiring CategoricalCrossEntropy", err)
+	}
+}
+
+// negativeLogLikelihood returns mean negative logarithm of predicted probability of correct class
+func negativeLogLikelihood(network NN, items []TrainItem) float64 {
+	sum := 0.0
+	for _, item := range items {
+		_, probabilities := network.Predict(item.Values)
+		p, err := probabilities.At(0, int(item.Label))
+		if err != nil {
+			panic(err)
+		}
+		sum -= math.Log(p)
+	}
+	return sum / float64(len(items))
+}
+
+func TestSoftmaxLowerLossThanSigmoid(t *testing.T) {
+	train, test := blobs(150, 3, 1), blobs(60, 3, 2)
+	config := TrainConfig{Epochs: 20, MiniBatchSize: 10, Eta: 0.5, Options: []TrainOption{ShuffleSeed(1)}}
+	sigmoid := NewNN([]int{2, 8, 3}, WithSeed(1))
+	softmax := NewNN([]int{2, 8, 3}, WithSeed(1), WithActivation(Sigmoid{}, Softmax{}))
+	for _, network := range []NN{sigmoid, softmax} {
+		if _, err := network.TrainWithConfig(train, config); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sigmoidLoss, softmaxLoss := negativeLogLikelihood(sigmoid, test), negativeLogLikelihood(softmax, test)
+	if softmaxLoss >= sigmoidLoss {
+		t.Errorf("softmax loss %f is not lower than sigmoid loss %f", softmaxLoss, sigmoidLoss)
+	}
+}
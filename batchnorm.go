@@ -0,0 +1,227 @@
+package nn
+
+import (
+	"errors"
+	"math"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// BatchNorm normalizes weighted inputs of a layer to zero mean and unit variance per neuron and then scales them
+// by Gamma and shifts them by Beta, which are learned. During training mean and variance of the mini-batch are used,
+// outside of it running averages of them collected during training
+type BatchNorm struct {
+	Gamma       matrices.Matrix
+	Beta        matrices.Matrix
+	RunningMean matrices.Matrix
+	RunningVar  matrices.Matrix
+	// Momentum is weight of old running statistics when they are updated by statistics of new mini-batch
+	Momentum float64
+	// Epsilon is added to variance for numerical stability
+	Epsilon float64
+}
+
+// normCache holds values of forward pass through batch normalization needed by backward pass
+type normCache struct {
+	normalized matrices.Matrix
+	invStd     matrices.Matrix
+}
+
+// SetBatchNorm enables batch normalization of weighted inputs of every hidden layer for which enabled is true,
+// normalization is inserted between weighted input and activation of the layer
+func (network *NN) SetBatchNorm(enabled []bool) error {
+	if len(enabled) != len(network.layers)-2 {
+		return errors.New("nn: number of batch normalization flags must match number of hidden layers")
+	}
+	network.batchNorm = make([]*BatchNorm, len(enabled))
+	for i, on := range enabled {
+		if on {
+			network.batchNorm[i] = newBatchNorm(network.layers[i+1])
+		}
+	}
+	return nil
+}
+
+func newBatchNorm(n int) *BatchNorm {
+	return &BatchNorm{
+		Gamma:       matrices.Ones(1, n),
+		Beta:        matrices.Zeros(1, n),
+		RunningMean: matrices.Zeros(1, n),
+		RunningVar:  matrices.Ones(1, n),
+		Momentum:    0.9,
+		Epsilon:     1e-5,
+	}
+}
+
+// Copy creates copy of given batch normalization
+func (norm *BatchNorm) Copy() *BatchNorm {
+	return &BatchNorm{
+		Gamma:       norm.Gamma.Copy(),
+		Beta:        norm.Beta.Copy(),
+		RunningMean: norm.RunningMean.Copy(),
+		RunningVar:  norm.RunningVar.Copy(),
+		Momentum:    norm.Momentum,
+		Epsilon:     norm.Epsilon,
+	}
+}
+
+// norm returns batch normalization of layer transition i or nil when it is not normalized
+func (network NN) norm(i int) *BatchNorm {
+	if i < len(network.batchNorm) {
+		return network.batchNorm[i]
+	}
+	return nil
+}
+
+func (network NN) hasBatchNorm() bool {
+	for _, norm := range network.batchNorm {
+		if norm != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// statistics returns mean and variance of every column of z
+func statistics(z matrices.Matrix) (mean, variance matrices.Matrix) {
+	mean, err := z.MeanAxis(0)
+	if err != nil {
+		panic(err)
+	}
+	centered, err := z.AddBroadcast(mean.ScalarMult(-1))
+	if err != nil {
+		panic(err)
+	}
+	if variance, err = centered.Apply(matrices.Square).MeanAxis(0); err != nil {
+		panic(err)
+	}
+	return mean, variance
+}
+
+// forward normalizes rows of z with statistics of z during training and with running statistics otherwise
+func (norm *BatchNorm) forward(z matrices.Matrix, training bool) (matrices.Matrix, normCache) {
+	mean, variance := norm.RunningMean, norm.RunningVar
+	if training {
+		mean, variance = statistics(z)
+	}
+	invStd := variance.Apply(matrices.Add(norm.Epsilon)).Apply(math.Sqrt).Apply(matrices.Invert)
+	centered, err := z.AddBroadcast(mean.ScalarMult(-1))
+	if err != nil {
+		panic(err)
+	}
+	normalized, err := centered.MultBroadcast(invStd)
+	if err != nil {
+		panic(err)
+	}
+	scaled, err := normalized.MultBroadcast(norm.Gamma)
+	if err != nil {
+		panic(err)
+	}
+	y, err := scaled.AddBroadcast(norm.Beta)
+	if err != nil {
+		panic(err)
+	}
+	return y, normCache{normalized, invStd}
+}
+
+// backward returns gradient of cost for input of normalization together with gradients for Gamma and Beta
+// from gradient delta for its output
+func (norm *BatchNorm) backward(delta matrices.Matrix, cache normCache, training bool) (nablaZ, nablaGamma, nablaBeta matrices.Matrix) {
+	rows := float64(delta.Rows())
+	weighted, err := delta.Mult(cache.normalized)
+	if err != nil {
+		panic(err)
+	}
+	if nablaGamma, err = weighted.MeanAxis(0); err != nil {
+		panic(err)
+	}
+	if nablaBeta, err = delta.MeanAxis(0); err != nil {
+		panic(err)
+	}
+	nablaGamma, nablaBeta = nablaGamma.ScalarMult(rows), nablaBeta.ScalarMult(rows)
+
+	nablaNormalized, err := delta.MultBroadcast(norm.Gamma)
+	if err != nil {
+		panic(err)
+	}
+	if training {
+		// batch statistics depend on every row, so gradient is centered and decorrelated from normalized input:
+		// (dx̂ - mean(dx̂) - x̂ * mean(dx̂ * x̂)) / std
+		meanNabla, err := nablaNormalized.MeanAxis(0)
+		if err != nil {
+			panic(err)
+		}
+		product, err := nablaNormalized.Mult(cache.normalized)
+		if err != nil {
+			panic(err)
+		}
+		meanProduct, err := product.MeanAxis(0)
+		if err != nil {
+			panic(err)
+		}
+		correction, err := cache.normalized.MultBroadcast(meanProduct)
+		if err != nil {
+			panic(err)
+		}
+		if nablaNormalized, err = nablaNormalized.AddBroadcast(meanNabla.ScalarMult(-1)); err != nil {
+			panic(err)
+		}
+		if nablaNormalized, err = nablaNormalized.Sub(correction); err != nil {
+			panic(err)
+		}
+	}
+	if nablaZ, err = nablaNormalized.MultBroadcast(cache.invStd); err != nil {
+		panic(err)
+	}
+	return nablaZ, nablaGamma, nablaBeta
+}
+
+// updateRunningStatistics moves running statistics of every batch normalization towards statistics
+// of weighted inputs of rows of x
+func (network NN) updateRunningStatistics(x matrices.Matrix) {
+	activation := x
+	for i := range network.weights {
+		z, err := network.preActivation(i, activation)
+		if err != nil {
+			panic(err)
+		}
+		if norm := network.norm(i); norm != nil {
+			mean, variance := statistics(z)
+			if norm.RunningMean, err = norm.RunningMean.ScalarMult(norm.Momentum).Add(mean.ScalarMult(1 - norm.Momentum)); err != nil {
+				panic(err)
+			}
+			if norm.RunningVar, err = norm.RunningVar.ScalarMult(norm.Momentum).Add(variance.ScalarMult(1 - norm.Momentum)); err != nil {
+				panic(err)
+			}
+			z, _ = norm.forward(z, true)
+		}
+		activation = network.activation(i).Apply(z)
+	}
+}
+
+// parameters returns weights followed by scales of batch normalizations and biases followed by their shifts,
+// in the same order as gradients returned by backpropRows
+func (network NN) parameters() (weights, biases []matrices.Matrix) {
+	weights = append([]matrices.Matrix(nil), network.weights...)
+	biases = append([]matrices.Matrix(nil), network.biases...)
+	for _, norm := range network.batchNorm {
+		if norm != nil {
+			weights = append(weights, norm.Gamma)
+			biases = append(biases, norm.Beta)
+		}
+	}
+	return weights, biases
+}
+
+// setParameters sets weights, biases and scales and shifts of batch normalizations in order returned by parameters
+func (network NN) setParameters(weights, biases []matrices.Matrix) {
+	copy(network.weights, weights)
+	copy(network.biases, biases)
+	k := len(network.weights)
+	for _, norm := range network.batchNorm {
+		if norm != nil {
+			norm.Gamma, norm.Beta = weights[k], biases[k]
+			k++
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package nn
+
+import "testing"
+
+func TestBatchNormGradientsDoNotDependOnWorkers(t *testing.T) {
+	network := NewNN([]int{2, 8, 3}, WithSeed(1))
+	if err := network.SetBatchNorm([]bool{true}); err != nil {
+		t.Fatal(err)
+	}
+	batch := blobs(30, 3, 1)
+	serialW, serialB := network.parallelBackprop(batch, newTrainOptions([]TrainOption{Workers(1)}))
+	parallelW, parallelB := network.parallelBackprop(batch, newTrainOptions([]TrainOption{Workers(10)}))
+	equalMatrices(t, "weights", parallelW, serialW, 1e-12)
+	equalMatrices(t, "biases", parallelB, serialB, 1e-12)
+}
+
+func TestRestoreBatchNorm(t *testing.T) {
+	network := batchNormNetwork(t)
+	saved := network.Copy()
+	for _, batch := range miniBatches(blobs(40, 2, 1), 10) {
+		network.updateMiniBatch(batch, 0.5, 0, 40, 0, newTrainOptions(nil))
+	}
+	network.restore(saved)
+	equalNetworks(t, network, saved)
+}
@@ -0,0 +1,80 @@
+package nn
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Option configures network created by NewNN
+type Option func(*networkOptions)
+
+type networkOptions struct {
+	activations []Activation
+	compensated bool
+	initializer Initializer
+	optimizer   Optimizer
+	rng         *rand.Rand
+}
+
+// WithActivation sets activations of layer transitions, single activation is used for all of them,
+// otherwise one activation for every transition must be given. Sigmoid is used by default.
+// Softmax is only allowed for output layer, so it cannot be the single activation of network with hidden layers
+func WithActivation(activations ...Activation) Option {
+	return func(options *networkOptions) {
+		options.activations = activations
+	}
+}
+
+// WithCompensatedDot makes forward pass of network sum weighted inputs with compensated summation,
+// see SetCompensatedDot
+func WithCompensatedDot() Option {
+	return func(options *networkOptions) {
+		options.compensated = true
+	}
+}
+
+// WithInitializer sets distribution of initial weights, NormalizedInit is used by default
+func WithInitializer(initializer Initializer) Option {
+	return func(options *networkOptions) {
+		options.initializer = initializer
+	}
+}
+
+// WithOptimizer sets optimizer used by training of network unless UseOptimizer training option overrides it,
+// plain SGD is used by default
+func WithOptimizer(optimizer Optimizer) Option {
+	return func(options *networkOptions) {
+		options.optimizer = optimizer
+	}
+}
+
+// WithSeed draws initial weights and biases from source seeded by given seed, so networks created
+// with equal seed are identical
+func WithSeed(seed int64) Option {
+	return func(options *networkOptions) {
+		options.rng = rand.New(rand.NewSource(seed))
+	}
+}
+
+// NewNN creates new randomly initialized neural network with given number of neurons in each layer
+// configured by given options, it panics on invalid configuration like InitNN
+func NewNN(layers []int, opts ...Option) NN {
+	var options networkOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if len(layers) < 2 {
+		panic(errors.New("nn: network must have at least input and output layer"))
+	}
+	activations := options.activations
+	if len(activations) == 1 && len(layers) > 2 {
+		activations = make([]Activation, len(layers)-1)
+		for i := range activations {
+			activations[i] = options.activations[0]
+		}
+	}
+	network := InitNNWithRand(layers, options.rng, options.initializer, activations...)
+	network.optimizer = options.optimizer
+	network.compensatedDot = options.compensated
+	return network
+}
@@ -0,0 +1,71 @@
+package nn
+
+import (
+	"errors"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// SetClassWeights sets weight of every class, cost of classification item and its gradients are multiplied
+// by weight of its Label, so under-represented classes can contribute more. Items with Target are not weighted,
+// nil weights disable weighting
+func (network *NN) SetClassWeights(weights []float64) error {
+	if weights == nil {
+		network.classWeights = nil
+		return nil
+	}
+	if len(weights) != network.layers[len(network.layers)-1] {
+		return errors.New("nn: number of class weights must match number of network outputs")
+	}
+	for _, weight := range weights {
+		if weight < 0 {
+			return errors.New("nn: class weights must not be negative")
+		}
+	}
+	network.classWeights = make([]float64, len(weights))
+	copy(network.classWeights, weights)
+	return nil
+}
+
+// BalancedClassWeights returns inverse-frequency weight of every class of items, n/(classes*count),
+// so that every class contributes to cost equally. Classes without items get weight 1
+func BalancedClassWeights(items []TrainItem) []float64 {
+	classes := 0
+	for _, item := range items {
+		if item.Distinct > classes {
+			classes = item.Distinct
+		}
+	}
+	counts := make([]int, classes)
+	for _, item := range items {
+		counts[int(item.Label)]++
+	}
+	weights := make([]float64, classes)
+	for class, count := range counts {
+		weights[class] = 1
+		if count > 0 {
+			weights[class] = float64(len(items)) / float64(classes*count)
+		}
+	}
+	return weights
+}
+
+// itemWeight returns weight of cost of given item
+func (network NN) itemWeight(item TrainItem) float64 {
+	if network.classWeights == nil || !item.Target.Empty() {
+		return 1
+	}
+	return network.classWeights[int(item.Label)]
+}
+
+// rowWeights returns weights of items of batch as column vector, or empty matrix when classes are not weighted
+func (network NN) rowWeights(batch []TrainItem) matrices.Matrix {
+	if network.classWeights == nil {
+		return matrices.Matrix{}
+	}
+	weights := matrices.InitMatrix(len(batch), 1)
+	for i, item := range batch {
+		weights.Set(i, 0, network.itemWeight(item))
+	}
+	return weights
+}
@@ -0,0 +1,41 @@
+package nn
+
+import (
+	"math"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// ClipByGlobalNorm returns matrices scaled by maxNorm/globalNorm when their global L2 norm,
+// computed over all elements of all matrices, exceeds maxNorm, otherwise returns copies of them
+func ClipByGlobalNorm(mats []matrices.Matrix, maxNorm float64) []matrices.Matrix {
+	norm := globalNorm(mats)
+	scale := 1.0
+	if norm > maxNorm {
+		scale = maxNorm / norm
+	}
+	clipped := make([]matrices.Matrix, len(mats))
+	for i, m := range mats {
+		clipped[i] = m.ScalarMult(scale)
+	}
+	return clipped
+}
+
+// ClipByValue returns copies of matrices with every element clamped into [-c, c]
+func ClipByValue(mats []matrices.Matrix, c float64) []matrices.Matrix {
+	clipped := make([]matrices.Matrix, len(mats))
+	for i, m := range mats {
+		clipped[i] = m.Clip(-c, c)
+	}
+	return clipped
+}
+
+// globalNorm returns L2 norm of all elements of given matrices
+func globalNorm(mats []matrices.Matrix) float64 {
+	sum := 0.0
+	for _, m := range mats {
+		norm := m.FrobeniusNorm()
+		sum += norm * norm
+	}
+	return math.Sqrt(sum)
+}
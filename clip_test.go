@@ -0,0 +1,44 @@
+package nn
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+func TestClipByGlobalNorm(t *testing.T) {
+	// global norm of gradients is sqrt(3² + 4² + 12²) = 13
+	gradients := []matrices.Matrix{
+		matrices.InitMatrixWithValues(2, []float64{3, 4}),
+		matrices.InitMatrixWithValues(1, []float64{-12}),
+	}
+	clipped := ClipByGlobalNorm(gradients, 1)
+	if norm := globalNorm(clipped); math.Abs(norm-1) > 1e-12 {
+		t.Errorf("norm of clipped gradients = %f, want 1", norm)
+	}
+	want := []matrices.Matrix{
+		matrices.InitMatrixWithValues(2, []float64{3.0 / 13, 4.0 / 13}),
+		matrices.InitMatrixWithValues(1, []float64{-12.0 / 13}),
+	}
+	equalMatrices(t, "clipped", clipped, want, 1e-12)
+	// gradients within bound are kept unchanged
+	equalMatrices(t, "unclipped", ClipByGlobalNorm(gradients, 20), gradients, 1e-12)
+}
+
+func TestClipGradientNormBoundsUpdate(t *testing.T) {
+	network := saturatedNetwork(t, Sigmoid{})
+	before := copyMatrices(append(network.Weights(), network.Biases()...))
+	items := []TrainItem{InitTrainItem([]float64{100, -100}, 1, 2)}
+	network.updateMiniBatch(items, 1, 0, 1, 0, newTrainOptions([]TrainOption{ClipGradientNorm(0.5)}))
+	steps := make([]matrices.Matrix, len(before))
+	for i, m := range append(network.Weights(), network.Biases()...) {
+		var err error
+		if steps[i], err = m.Sub(before[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if norm := globalNorm(steps); math.Abs(norm-0.5) > 1e-9 {
+		t.Errorf("norm of update with gradients clipped to 0.5 = %f, want 0.5", norm)
+	}
+}
@@ -0,0 +1,97 @@
+package nn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// TrainConfig holds settings of training, zero values of Epochs, MiniBatchSize and Eta are replaced by defaults
+type TrainConfig struct {
+	// Epochs is number of epochs, 30 by default. With BestOfN it is number of epochs without improvement
+	// of cost on TestData after which training stops, or learning rate is halved when EtaFraction allows it
+	Epochs  int
+	BestOfN bool
+	// MiniBatchSize is number of items of every update, 10 by default
+	MiniBatchSize int
+	// Eta is learning rate, 0.5 by default
+	Eta float64
+	// EtaFraction limits halving of learning rate with BestOfN, learning rate is halved
+	// only while Eta*EtaFraction stays above original Eta
+	EtaFraction float64
+	// Lambda is strength of L2 regularization
+	Lambda float64
+	// TestData is used to report validation cost and accuracy, BestOfN and early stopping require it
+	TestData []TrainItem
+	// PrintCost logs validation cost of every epoch together with accuracy
+	PrintCost bool
+	// Options configure optional behavior of training
+	Options []TrainOption
+}
+
+// trainConfig creates configuration from positional parameters of Train, which are used as given
+func trainConfig(epochs, miniBatchSize int, eta, etaFraction, lmbda float64, testData []TrainItem, printCost bool, opts []TrainOption) TrainConfig {
+	return TrainConfig{
+		Epochs:        epochs,
+		MiniBatchSize: miniBatchSize,
+		Eta:           eta,
+		EtaFraction:   etaFraction,
+		Lambda:        lmbda,
+		TestData:      testData,
+		PrintCost:     printCost,
+		Options:       opts,
+	}
+}
+
+func (config TrainConfig) withDefaults() TrainConfig {
+	if config.Epochs == 0 {
+		config.Epochs = 30
+	}
+	if config.MiniBatchSize == 0 {
+		config.MiniBatchSize = 10
+	}
+	if config.Eta == 0 {
+		config.Eta = 0.5
+	}
+	return config
+}
+
+// validate checks configuration and inputs before training starts
+func (config TrainConfig) validate(inputs []TrainItem) error {
+	if len(inputs) == 0 {
+		return errors.New("nn: training requires at least one input")
+	}
+	if config.Epochs == 0 {
+		return errors.New("nn: number of epochs must not be zero")
+	}
+	if config.MiniBatchSize <= 0 {
+		return fmt.Errorf("nn: mini-batch size must be positive, got %d", config.MiniBatchSize)
+	}
+	distinct := inputs[0].Distinct
+	for i, item := range inputs {
+		if item.Distinct != distinct {
+			return fmt.Errorf("nn: input %d has %d distinct classes, first input has %d", i, item.Distinct, distinct)
+		}
+	}
+	for i, item := range config.TestData {
+		if item.Distinct != distinct {
+			return fmt.Errorf("nn: test item %d has %d distinct classes, inputs have %d", i, item.Distinct, distinct)
+		}
+	}
+	// without test data cost never improves, so training would stop early and restore initial weights
+	if len(config.TestData) == 0 {
+		if config.BestOfN || config.Epochs < 0 {
+			return errors.New("nn: best-of-N training requires test data")
+		}
+		if newTrainOptions(config.Options).earlyStopping != nil {
+			return errors.New("nn: early stopping requires test data")
+		}
+	}
+	return nil
+}
+
+// TrainWithConfig trains Network with given configuration and returns recorded History,
+// progress is logged only when LogTo option is given. Invalid configuration or inputs result in error
+func (network NN) TrainWithConfig(inputs []TrainItem, config TrainConfig) (History, error) {
+	return network.train(context.Background(), inputs, config.withDefaults(), nil)
+}
@@ -0,0 +1,33 @@
+package nn
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateRequiresTestData(t *testing.T) {
+	inputs := XORDataset()
+	for name, config := range map[string]TrainConfig{
+		"early stopping":  {Epochs: 10, MiniBatchSize: 2, Options: []TrainOption{EarlyStop(EarlyStopping{Patience: 2, RestoreBest: true})}},
+		"best-of-N":       {Epochs: 10, MiniBatchSize: 2, BestOfN: true},
+		"negative epochs": {Epochs: -10, MiniBatchSize: 2},
+	} {
+		if err := config.validate(inputs); err == nil || !strings.Contains(err.Error(), "requires test data") {
+			t.Errorf("%s: err = %v, want error requiring test data", name, err)
+		}
+		config.TestData = inputs
+		if err := config.validate(inputs); err != nil {
+			t.Errorf("%s with test data: %v", name, err)
+		}
+	}
+}
+
+func TestTrainWithErrorReportsInvalidSettings(t *testing.T) {
+	network := NewNN([]int{2, 3, 2}, WithSeed(1))
+	if _, err := network.TrainWithError(XORDataset(), 5, 0, 0.5, 0, 0, nil, false); err == nil || !strings.Contains(err.Error(), "mini-batch size") {
+		t.Errorf("err = %v, want error about mini-batch size", err)
+	}
+	if _, err := network.TrainWithError(XORDataset(), 5, 2, 0.5, 0, 0, nil, false); err != nil {
+		t.Errorf("valid settings: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package nn
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// CostFunction selects cost minimized by training
+type CostFunction int
+
+const (
+	// CrossEntropy is cross-entropy cost for classification, default cost of network
+	CrossEntropy CostFunction = iota
+	// MeanSquaredError is quadratic cost 0.5*||output - target||^2 for regression
+	MeanSquaredError
+	// CategoricalCrossEntropy is cost -sum(target * log(output)) of mutually exclusive classes,
+	// it is meant for Softmax output activation
+	CategoricalCrossEntropy
+)
+
+var costNames = map[CostFunction]string{
+	CrossEntropy:            "cross-entropy",
+	MeanSquaredError:        "mse",
+	CategoricalCrossEntropy: "categorical-cross-entropy",
+}
+
+func (cost CostFunction) String() string {
+	if name, ok := costNames[cost]; ok {
+		return name
+	}
+	return fmt.Sprintf("CostFunction(%d)", int(cost))
+}
+
+// MarshalJSON implements Marshaler interface
+func (cost CostFunction) MarshalJSON() ([]byte, error) {
+	name, ok := costNames[cost]
+	if !ok {
+		return nil, fmt.Errorf("nn: unknown cost function %d", int(cost))
+	}
+	return json.Marshal(name)
+}
+
+// UnmarshalJSON implements Unmarshaler interface
+func (cost *CostFunction) UnmarshalJSON(serialized []byte) error {
+	var name string
+	if err := json.Unmarshal(serialized, &name); err != nil {
+		return err
+	}
+	for function, functionName := range costNames {
+		if functionName == name {
+			*cost = function
+			return nil
+		}
+	}
+	return fmt.Errorf("nn: unknown cost function %q", name)
+}
+
+// SetCostFunction sets cost minimized by training and reported by Cost
+func (network *NN) SetCostFunction(cost CostFunction) {
+	network.cost = cost
+}
+
+// defaultCostEpsilon is bound of outputs of cross-entropy used unless SetCostEpsilon changes it
+const defaultCostEpsilon = 1e-12
+
+// SetCostEpsilon sets bound of outputs, which are clipped into [epsilon, 1-epsilon] before taking logarithm
+// of cross-entropy, so saturated outputs give finite cost. It must lie in (0, 0.5), 1e-12 is used by default
+func (network *NN) SetCostEpsilon(epsilon float64) error {
+	if !(epsilon > 0 && epsilon < 0.5) {
+		return fmt.Errorf("nn: cost epsilon must lie in (0, 0.5), got %g", epsilon)
+	}
+	network.costEpsilon = epsilon
+	return nil
+}
+
+// epsilon returns bound of outputs of cross-entropy
+func (network NN) epsilon() float64 {
+	if network.costEpsilon == 0 {
+		return defaultCostEpsilon
+	}
+	return network.costEpsilon
+}
+
+// EvaluateRegression returns mean squared error over all outputs of all inputs and coefficient
+// of determination R² of network outputs against item targets, outputs are mapped back to original units
+// when network has target scaler. Both are NaN without inputs
+func (network NN) EvaluateRegression(inputs []TrainItem) (mse, r2 float64) {
+	if len(inputs) == 0 {
+		return math.NaN(), math.NaN()
+	}
+	var mean matrices.Matrix
+	residual := 0.0
+	count := 0
+	for i, input := range inputs {
+		y, err := input.target()
+		if err != nil {
+			panic(err)
+		}
+		diff, err := network.PredictValues(input.Values).Sub(y)
+		if err != nil {
+			panic(err)
+		}
+		residual += diff.Apply(matrices.Square).Sum()
+		count += y.Cols()
+		if i == 0 {
+			mean = y.Copy()
+		} else if mean, err = mean.Add(y); err != nil {
+			panic(err)
+		}
+	}
+	mean = mean.ScalarMult(1 / float64(len(inputs)))
+	total := 0.0
+	for _, input := range inputs {
+		y, _ := input.target()
+		diff, err := y.Sub(mean)
+		if err != nil {
+			panic(err)
+		}
+		total += diff.Apply(matrices.Square).Sum()
+	}
+	mse = residual / float64(count)
+	if total == 0 {
+		return mse, math.NaN()
+	}
+	return mse, 1 - residual/total
+}
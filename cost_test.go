@@ -0,0 +1,60 @@
+package nn
+
+import (
+	"math"
+	"testing"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// saturatedNetwork returns network whose outputs are exactly 1 and 0 for every input
+func saturatedNetwork(t *testing.T, activations ...Activation) NN {
+	t.Helper()
+	network := NewNN([]int{2, 2}, WithActivation(activations...))
+	if err := network.SetBiases([]matrices.Matrix{matrices.InitMatrixWithValues(2, []float64{1000, -1000})}); err != nil {
+		t.Fatal(err)
+	}
+	return network
+}
+
+func TestSaturatedCostIsFinite(t *testing.T) {
+	// item of class 1 gets output 0 for its class, so its cost is -log of clipped output
+	items := []TrainItem{InitTrainItem([]float64{0, 0}, 1, 2)}
+	for name, network := range map[string]NN{
+		"sigmoid": saturatedNetwork(t, Sigmoid{}),
+		"softmax": saturatedNetwork(t, Softmax{}),
+	} {
+		if cost := network.Cost(items); math.IsNaN(cost) || math.IsInf(cost, 0) {
+			t.Errorf("%s: cost of saturated network = %f", name, cost)
+		}
+	}
+}
+
+func TestSetCostEpsilon(t *testing.T) {
+	items := []TrainItem{InitTrainItem([]float64{0, 0}, 1, 2)}
+	network := saturatedNetwork(t, Softmax{})
+	if cost, want := network.Cost(items), -math.Log(defaultCostEpsilon); math.Abs(cost-want) > 1e-9 {
+		t.Errorf("cost with default epsilon = %f, want %f", cost, want)
+	}
+	if err := network.SetCostEpsilon(1e-3); err != nil {
+		t.Fatal(err)
+	}
+	if cost, want := network.Cost(items), -math.Log(1e-3); math.Abs(cost-want) > 1e-9 {
+		t.Errorf("cost with epsilon 1e-3 = %f, want %f", cost, want)
+	}
+	for _, epsilon := range []float64{0, -1, 0.5, math.NaN()} {
+		if err := network.SetCostEpsilon(epsilon); err == nil {
+			t.Errorf("epsilon %f was accepted", epsilon)
+		}
+	}
+}
+
+func TestEmptyInputsGiveNaN(t *testing.T) {
+	network := NewNN([]int{2, 3, 2}, WithSeed(1))
+	mse, r2 := network.EvaluateRegression(nil)
+	for name, value := range map[string]float64{"mse": mse, "R²": r2, "Cost": network.Cost(nil), "Evaluate": network.Evaluate(nil)} {
+		if !math.IsNaN(value) {
+			t.Errorf("%s of empty inputs = %g, want NaN", name, value)
+		}
+	}
+}
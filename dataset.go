@@ -0,0 +1,163 @@
+package nn
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadLibSVM loads training items from file in libsvm sparse format ("label idx:val idx:val ..."),
+// indices are one-based and each item gets dense values of numFeatures width. Labels must be whole numbers
+// in [0, distinct), so -1/+1 labels of binary datasets have to be remapped first
+func LoadLibSVM(path string, numFeatures, distinct int) ([]TrainItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var items []TrainItem
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if comment := strings.IndexByte(line, '#'); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		label, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil || label != math.Trunc(label) || label < 0 || label >= float64(distinct) {
+			return nil, fmt.Errorf("nn: libsvm line %d: invalid label %q, expected whole number in [0, %d)", lineNumber, fields[0], distinct)
+		}
+		values := make([]float64, numFeatures)
+		for _, field := range fields[1:] {
+			parts := strings.SplitN(field, ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("nn: libsvm line %d: invalid feature %q", lineNumber, field)
+			}
+			index, err := strconv.Atoi(parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("nn: libsvm line %d: invalid feature index %q", lineNumber, parts[0])
+			}
+			if index < 1 || index > numFeatures {
+				return nil, fmt.Errorf("nn: libsvm line %d: feature index %d out of range [1, %d]", lineNumber, index, numFeatures)
+			}
+			value, err := strconv.ParseFloat(parts[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("nn: libsvm line %d: invalid feature value %q", lineNumber, parts[1])
+			}
+			values[index-1] = value
+		}
+		items = append(items, InitTrainItem(values, label, distinct))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// CSVOption configures optional behavior of LoadCSV
+type CSVOption func(*csvOptions)
+
+type csvOptions struct {
+	header bool
+	comma  rune
+}
+
+// CSVHeader skips first row of CSV file, which holds column names
+func CSVHeader() CSVOption {
+	return func(options *csvOptions) {
+		options.header = true
+	}
+}
+
+// CSVComma sets field delimiter of CSV file, comma is used by default
+func CSVComma(comma rune) CSVOption {
+	return func(options *csvOptions) {
+		options.comma = comma
+	}
+}
+
+// LoadCSV loads training items from CSV file where column labelCol holds integer class label in [0, distinct)
+// and all other columns hold feature values in their order, all rows must have the same number of columns
+func LoadCSV(path string, labelCol, distinct int, opts ...CSVOption) ([]TrainItem, error) {
+	options := csvOptions{comma: ','}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comma = options.comma
+	reader.TrimLeadingSpace = true
+	var items []TrainItem
+	for lineNumber := 1; ; lineNumber++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("nn: csv: %v", err)
+		}
+		if lineNumber == 1 && options.header {
+			continue
+		}
+		if labelCol < 0 || labelCol >= len(record) {
+			return nil, fmt.Errorf("nn: csv line %d: label column %d out of range [0, %d)", lineNumber, labelCol, len(record))
+		}
+		label, err := strconv.Atoi(strings.TrimSpace(record[labelCol]))
+		if err != nil || label < 0 || label >= distinct {
+			return nil, fmt.Errorf("nn: csv line %d: invalid label %q, expected integer in [0, %d)", lineNumber, record[labelCol], distinct)
+		}
+		values := make([]float64, 0, len(record)-1)
+		for col, cell := range record {
+			if col == labelCol {
+				continue
+			}
+			value, err := strconv.ParseFloat(strings.TrimSpace(cell), 64)
+			if err != nil {
+				return nil, fmt.Errorf("nn: csv line %d: invalid value %q in column %d", lineNumber, cell, col)
+			}
+			values = append(values, value)
+		}
+		items = append(items, InitTrainItem(values, float64(label), distinct))
+	}
+	return items, nil
+}
+
+// ValidateDataset checks that every item fits network - it has one row of values matching input layer,
+// Distinct matching output layer and, for classification items, whole Label in range [0, Distinct).
+// First inconsistency found is returned
+func ValidateDataset(items []TrainItem, network NN) error {
+	inputs, outputs := network.layers[0], network.layers[len(network.layers)-1]
+	for i, item := range items {
+		if item.Values.Rows() != 1 || item.Values.Cols() != inputs {
+			return fmt.Errorf("nn: item %d has %dx%d values, network expects 1x%d", i, item.Values.Rows(), item.Values.Cols(), inputs)
+		}
+		if item.Distinct != outputs {
+			return fmt.Errorf("nn: item %d has %d distinct classes, network has %d outputs", i, item.Distinct, outputs)
+		}
+		if !item.Target.Empty() {
+			if item.Target.Rows() != 1 || item.Target.Cols() != outputs {
+				return fmt.Errorf("nn: item %d has %dx%d target, network expects 1x%d", i, item.Target.Rows(), item.Target.Cols(), outputs)
+			}
+		} else if item.Label < 0 || item.Label >= float64(item.Distinct) || item.Label != math.Trunc(item.Label) {
+			return fmt.Errorf("nn: item %d has label %v, which is not a class in [0, %d)", i, item.Label, item.Distinct)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package nn
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content into file of test's temporary directory and returns its path
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLibSVM(t *testing.T) {
+	items, err := LoadLibSVM(writeFile(t, "1 1:0.5 3:2 # comment\n\n0.0 2:-1\n"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Label != 1 || items[1].Label != 0 {
+		t.Fatalf("items = %v, want labels 1 and 0", items)
+	}
+	if value, _ := items[0].Values.At(0, 2); value != 2 {
+		t.Errorf("third value of first item = %g, want 2", value)
+	}
+}
+
+func TestLoadLibSVMRejectsInvalidLabels(t *testing.T) {
+	for _, label := range []string{"-1", "+1.5", "2", "NaN", "x"} {
+		_, err := LoadLibSVM(writeFile(t, label+" 1:1\n"), 1, 2)
+		if err == nil || !strings.Contains(err.Error(), "invalid label") {
+			t.Errorf("label %s: err = %v, want invalid label error", label, err)
+		}
+	}
+}
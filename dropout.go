@@ -0,0 +1,43 @@
+package nn
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// SetDropout sets rate of inverted dropout for each hidden layer. During training given fraction of layer
+// activations is zeroed and the rest is scaled by 1/(1-rate), FeedForward never drops anything
+func (network *NN) SetDropout(rates []float64) error {
+	if len(rates) != len(network.layers)-2 {
+		return errors.New("nn: number of dropout rates must match number of hidden layers")
+	}
+	for _, rate := range rates {
+		if rate < 0 || rate >= 1 {
+			return errors.New("nn: dropout rate must be in [0, 1)")
+		}
+	}
+	network.dropout = make([]float64, len(rates))
+	copy(network.dropout, rates)
+	return nil
+}
+
+func (network NN) hasDropout() bool {
+	for _, rate := range network.dropout {
+		if rate > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dropoutMask returns matrix with zero for dropped activations and 1/(1-rate) for kept ones
+func dropoutMask(rows, cols int, rate float64, rng *rand.Rand) matrices.Matrix {
+	return matrices.InitMatrix(rows, cols).Apply(func(float64) float64 {
+		if rng.Float64() < rate {
+			return 0
+		}
+		return 1 / (1 - rate)
+	})
+}
@@ -0,0 +1,28 @@
+package nn
+
+import "github.com/tek-shinobi/back-propagation-nn/matrices"
+
+// InputGradient returns gradient of cost of given item with respect to its input values
+func (network NN) InputGradient(item TrainItem) matrices.Matrix {
+	_, _, nablaX := network.gradients(item)
+	return nablaX
+}
+
+// FeatureImportance returns absolute input gradient of given item normalized to sum to one,
+// higher value means prediction is more sensitive to that feature
+func (network NN) FeatureImportance(item TrainItem) []float64 {
+	saliency := network.InputGradient(item).Abs()
+	total := saliency.Sum()
+	importance := make([]float64, saliency.Cols())
+	for i := range importance {
+		value, err := saliency.At(0, i)
+		if err != nil {
+			panic(err)
+		}
+		if total > 0 {
+			value /= total
+		}
+		importance[i] = value
+	}
+	return importance
+}
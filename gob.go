@@ -0,0 +1,49 @@
+package nn
+
+import (
+	"bytes"
+	"encoding/gob"
+	"os"
+)
+
+// GobEncode implements GobEncoder interface
+func (network NN) GobEncode() ([]byte, error) {
+	exported, err := network.export()
+	if err != nil {
+		return nil, err
+	}
+	var buffer bytes.Buffer
+	err = gob.NewEncoder(&buffer).Encode(exported)
+	return buffer.Bytes(), err
+}
+
+// GobDecode implements GobDecoder interface
+func (network *NN) GobDecode(serialized []byte) error {
+	var exported exportedNetwork
+	if err := gob.NewDecoder(bytes.NewReader(serialized)).Decode(&exported); err != nil {
+		return err
+	}
+	return network.load(exported)
+}
+
+// SaveGob exports network to file in gob format, which is much smaller and faster to load than JSON
+func (network NN) SaveGob(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return gob.NewEncoder(f).Encode(network)
+}
+
+// LoadNetworkGob loads network from file saved by SaveGob
+func LoadNetworkGob(path string) (NN, error) {
+	var network NN
+	f, err := os.Open(path)
+	if err != nil {
+		return network, err
+	}
+	defer f.Close()
+	err = gob.NewDecoder(f).Decode(&network)
+	return network, err
+}
@@ -0,0 +1,77 @@
+package nn
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"testing"
+)
+
+// batchNormNetwork returns network whose first hidden layer is normalized and second is not
+func batchNormNetwork(t *testing.T) NN {
+	t.Helper()
+	network := NewNN([]int{2, 4, 3, 2}, WithSeed(1))
+	if err := network.SetBatchNorm([]bool{true, false}); err != nil {
+		t.Fatal(err)
+	}
+	network.batchNorm[0].Gamma.ApplyInPlace(func(f float64) float64 { return f * 1.5 })
+	network.batchNorm[0].RunningMean.ApplyInPlace(func(f float64) float64 { return f + 0.25 })
+	return network
+}
+
+// equalNetworks fails test unless both networks give equal outputs and batch normalizations
+func equalNetworks(t *testing.T, got, want NN) {
+	t.Helper()
+	for _, item := range XORDataset() {
+		if !got.FeedForward(item.Values).Equals(want.FeedForward(item.Values), 1e-12) {
+			t.Errorf("output for %v = %v, want %v", item.Values, got.FeedForward(item.Values), want.FeedForward(item.Values))
+		}
+	}
+	if len(got.batchNorm) != len(want.batchNorm) {
+		t.Fatalf("got %d batch normalizations, want %d", len(got.batchNorm), len(want.batchNorm))
+	}
+	for i, norm := range want.batchNorm {
+		if (norm == nil) != (got.batchNorm[i] == nil) {
+			t.Errorf("batch normalization of layer %d = %v, want %v", i, got.batchNorm[i], norm)
+		} else if norm != nil && !(got.batchNorm[i].Gamma.Equals(norm.Gamma, 1e-12) && got.batchNorm[i].RunningMean.Equals(norm.RunningMean, 1e-12)) {
+			t.Errorf("batch normalization of layer %d = %v, want %v", i, *got.batchNorm[i], *norm)
+		}
+	}
+}
+
+func TestGobRoundTripWithBatchNorm(t *testing.T) {
+	network := batchNormNetwork(t)
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(network); err != nil {
+		t.Fatal(err)
+	}
+	var decoded NN
+	if err := gob.NewDecoder(&buffer).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	equalNetworks(t, decoded, network)
+}
+
+func TestLoadVersion3BatchNorm(t *testing.T) {
+	network := batchNormNetwork(t)
+	serialized, err := json.Marshal(network)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// version 3 stored entry for every layer with null for layers without normalization
+	var legacy map[string]interface{}
+	if err := json.Unmarshal(serialized, &legacy); err != nil {
+		t.Fatal(err)
+	}
+	legacy["Version"] = 3
+	legacy["BatchNorm"] = []interface{}{legacy["BatchNorm"].([]interface{})[0], nil}
+	delete(legacy, "BatchNormLayers")
+	if serialized, err = json.Marshal(legacy); err != nil {
+		t.Fatal(err)
+	}
+	var loaded NN
+	if err := json.Unmarshal(serialized, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	equalNetworks(t, loaded, network)
+}
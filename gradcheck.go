@@ -0,0 +1,51 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// GradientCheck compares analytic gradients of cost of item computed by backpropagation with numerical ones
+// computed by central differences with step epsilon for every weight and bias,
+// and returns maximum relative error among them. Values around 1e-7 or lower mean gradients are right
+func (network NN) GradientCheck(item TrainItem, epsilon float64) (maxRelError float64, err error) {
+	if epsilon <= 0 {
+		return 0, errors.New("nn: gradient check step must be positive")
+	}
+	if item.Values.Rows() != 1 || item.Values.Cols() != network.layers[0] {
+		return 0, fmt.Errorf("nn: gradient check item must have 1×%d values", network.layers[0])
+	}
+	if _, err := network.target(item); err != nil {
+		return 0, err
+	}
+
+	// perturbations are done on copy so that network stays untouched even when used concurrently
+	perturbed := network.Copy()
+	nablaW, nablaB, _ := perturbed.gradients(item)
+	check := func(params, analytic []matrices.Matrix) {
+		for i, param := range params {
+			for row := 0; row < param.Rows(); row++ {
+				for col := 0; col < param.Cols(); col++ {
+					original, _ := param.At(row, col)
+					param.Set(row, col, original+epsilon)
+					plus := perturbed.itemCost(item)
+					param.Set(row, col, original-epsilon)
+					minus := perturbed.itemCost(item)
+					param.Set(row, col, original)
+
+					numerical := (plus - minus) / (2 * epsilon)
+					gradient, _ := analytic[i].At(row, col)
+					if scale := math.Abs(numerical) + math.Abs(gradient); scale > 0 {
+						maxRelError = math.Max(maxRelError, math.Abs(numerical-gradient)/scale)
+					}
+				}
+			}
+		}
+	}
+	check(perturbed.weights, nablaW)
+	check(perturbed.biases, nablaB)
+	return maxRelError, nil
+}
@@ -0,0 +1,39 @@
+package nn
+
+import "testing"
+
+func TestGradientCheck(t *testing.T) {
+	softmax := NewNN([]int{2, 4, 3, 3}, WithSeed(1), WithActivation(Tanh{}, ELU{Alpha: 1}, Softmax{}))
+	softmax.SetCostFunction(CategoricalCrossEntropy)
+	regression := NewNN([]int{2, 4, 2}, WithSeed(1), WithActivation(Tanh{}, Linear{}))
+	regression.SetCostFunction(MeanSquaredError)
+	weighted := NewNN([]int{2, 4, 2}, WithSeed(1))
+	if err := weighted.SetClassWeights([]float64{0.3, 2}); err != nil {
+		t.Fatal(err)
+	}
+	clamped := NewNN([]int{2, 4, 2}, WithSeed(1))
+	if err := clamped.SetPreActivationClamp([]float64{0.5, 0}); err != nil {
+		t.Fatal(err)
+	}
+	scaledItems := lineItems(20, 1)
+	for name, tc := range map[string]struct {
+		network NN
+		item    TrainItem
+	}{
+		"sigmoid":                   {NewNN([]int{2, 4, 2}, WithSeed(1)), blobs(2, 2, 1)[1]},
+		"tanh and ELU with softmax": {softmax, blobs(3, 3, 1)[2]},
+		"linear with mean squared":  {regression, InitRegressionItem([]float64{0.3, -0.7}, []float64{0.5, -1.5})},
+		"batch normalization":       {batchNormNetwork(t), blobs(2, 2, 1)[1]},
+		"class weights":             {weighted, blobs(2, 2, 1)[1]},
+		"pre-activation clamp":      {clamped, blobs(2, 2, 1)[1]},
+		"scaled regression targets": {scaledNetwork(scaledItems), scaledItems[0]},
+	} {
+		maxRelError, err := tc.network.GradientCheck(tc.item, 1e-5)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if maxRelError >= 1e-6 {
+			t.Errorf("%s: maximum relative error = %g, want below 1e-6", name, maxRelError)
+		}
+	}
+}
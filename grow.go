@@ -0,0 +1,65 @@
+package nn
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// Widen returns copy of network where hidden layer at given index has newWidth neurons. New neurons replicate
+// randomly chosen existing ones and outgoing weights of replicated neurons are split, so the widened network
+// computes the same function as the original (net2net wider transform). Replicated neurons are drawn from rng,
+// global source of math/rand is used when rng is nil. State of optimizer of widened network
+// no longer matches its weights, so it starts afresh
+func (network NN) Widen(layer, newWidth int, rng *rand.Rand) (NN, error) {
+	if layer <= 0 || layer >= len(network.layers)-1 {
+		return NN{}, errors.New("nn: only hidden layers can be widened")
+	}
+	if network.norm(layer-1) != nil {
+		return NN{}, errors.New("nn: layers with batch normalization cannot be widened")
+	}
+	oldWidth := network.layers[layer]
+	if newWidth < oldWidth {
+		return NN{}, errors.New("nn: widened layer cannot be narrower than original")
+	}
+
+	mapping := make([]int, newWidth)
+	counts := make([]int, oldWidth)
+	for j := range mapping {
+		if j < oldWidth {
+			mapping[j] = j
+		} else if rng == nil {
+			mapping[j] = rand.Intn(oldWidth)
+		} else {
+			mapping[j] = rng.Intn(oldWidth)
+		}
+		counts[mapping[j]]++
+	}
+
+	widened := network.Copy()
+	widened.layers[layer] = newWidth
+
+	incoming := network.weights[layer-1]
+	biases := network.biases[layer-1]
+	outgoing := network.weights[layer]
+	newIncoming := matrices.InitMatrix(incoming.Rows(), newWidth)
+	newBiases := matrices.InitMatrix(1, newWidth)
+	newOutgoing := matrices.InitMatrix(newWidth, outgoing.Cols())
+	for j, source := range mapping {
+		for i := 0; i < incoming.Rows(); i++ {
+			value, _ := incoming.At(i, source)
+			newIncoming.Set(i, j, value)
+		}
+		value, _ := biases.At(0, source)
+		newBiases.Set(0, j, value)
+		for k := 0; k < outgoing.Cols(); k++ {
+			value, _ := outgoing.At(source, k)
+			newOutgoing.Set(j, k, value/float64(counts[source]))
+		}
+	}
+	widened.weights[layer-1] = newIncoming
+	widened.biases[layer-1] = newBiases
+	widened.weights[layer] = newOutgoing
+	return widened, nil
+}
@@ -0,0 +1,34 @@
+package nn
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestWidenKeepsPredictions(t *testing.T) {
+	network := NewNN([]int{2, 4, 3, 2}, WithSeed(1), WithActivation(ReLU{}, Tanh{}, Sigmoid{}))
+	for _, layer := range []int{1, 2} {
+		widened, err := network.Widen(layer, 7, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range blobs(20, 2, 1) {
+			if got, want := widened.FeedForward(item.Values), network.FeedForward(item.Values); !got.Equals(want, 1e-12) {
+				t.Errorf("layer %d: widened output %v, want %v", layer, got, want)
+			}
+		}
+	}
+}
+
+func TestWidenIsReproducible(t *testing.T) {
+	network := NewNN([]int{2, 4, 2}, WithSeed(1))
+	first, err := network.Widen(1, 9, rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := network.Widen(1, 9, rand.New(rand.NewSource(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	equalMatrices(t, "weights", second.weights, first.weights, 1e-15)
+}
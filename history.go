@@ -0,0 +1,13 @@
+package nn
+
+// History holds values recorded during training
+type History struct {
+	// TrainingCost holds cost on training inputs after each epoch, Train records it only with RecordTrainingCost option
+	TrainingCost []float64
+	// ValidationCost holds cost on test data after each epoch, recorded only when test data are given
+	ValidationCost []float64
+	// ValidationAccuracy holds ratio of correctly classified test data after each epoch, recorded only when test data are given
+	ValidationAccuracy []float64
+	// BatchCost holds cost of each mini-batch just before it was used for update, recorded only with RecordBatchCost option
+	BatchCost []float64
+}
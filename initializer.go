@@ -0,0 +1,44 @@
+package nn
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// Initializer selects distribution of initial weights
+type Initializer int
+
+const (
+	// NormalizedInit draws weights from normal distribution divided by square root of fan-in, default of InitNN
+	NormalizedInit Initializer = iota
+	// XavierInit draws weights with standard deviation sqrt(2/(fan_in+fan_out)), suited for sigmoid and tanh layers
+	XavierInit
+	// HeInit draws weights with standard deviation sqrt(2/fan_in), suited for ReLU layers
+	HeInit
+)
+
+// matrix returns rows×cols weights drawn from rng, global source of math/rand is used when rng is nil
+func (initializer Initializer) matrix(rows, cols int, rng *rand.Rand) matrices.Matrix {
+	switch initializer {
+	case XavierInit:
+		return matrices.RandNormalMatrix(rng, rows, cols, math.Sqrt(2/float64(rows+cols)))
+	case HeInit:
+		return matrices.RandNormalMatrix(rng, rows, cols, math.Sqrt(2/float64(rows)))
+	}
+	return matrices.RandNormalMatrix(rng, rows, cols, 1/math.Sqrt(float64(rows)))
+}
+
+func initializerByName(name string) (Initializer, error) {
+	switch name {
+	case "", "normalized":
+		return NormalizedInit, nil
+	case "xavier", "glorot":
+		return XavierInit, nil
+	case "he":
+		return HeInit, nil
+	}
+	return 0, fmt.Errorf("nn: unknown initialization %q", name)
+}
@@ -0,0 +1,163 @@
+package matrices
+
+import (
+    "errors"
+    "math"
+)
+
+// singularTolerance returns the largest pivot magnitude LU decomposition of matrix treats as zero,
+// it is relative to scale of matrix so that matrices of small values are not taken as singular
+func (m Matrix) singularTolerance() float64 {
+    maxval := 0.0
+    for _, val := range m.values {
+        maxval = math.Max(maxval, math.Abs(val))
+    }
+    return float64(m.Rows()) * epsilon * maxval
+}
+
+// epsilon is difference between 1 and the next larger float64
+const epsilon = 0x1p-52
+
+// lu returns LU decomposition of square matrix with partial pivoting, L (without unit diagonal) and U
+// are stored together in one matrix, perm maps rows of result to rows of original matrix and sign
+// is determinant of the permutation. Singular matrix results in error
+func (m Matrix) lu() (lu Matrix, perm []int, sign float64, err error) {
+    if m.Rows() != m.Cols() {
+        return lu, nil, 0, errors.New("matrices: LU decomposition requires square matrix")
+    }
+    n := m.Rows()
+    lu = m.Copy()
+    perm = make([]int, n)
+    for i := range perm {
+        perm[i] = i
+    }
+    sign = 1
+    tolerance := m.singularTolerance()
+    for k := 0; k < n; k++ {
+        pivot := k
+        for i := k + 1; i < n; i++ {
+            if math.Abs(lu.at(i, k)) > math.Abs(lu.at(pivot, k)) {
+                pivot = i
+            }
+        }
+        if math.Abs(lu.at(pivot, k)) <= tolerance {
+            return lu, perm, 0, errors.New("matrices: matrix is singular")
+        }
+        if pivot != k {
+            lu.swapRows(k, pivot)
+            perm[k], perm[pivot] = perm[pivot], perm[k]
+            sign = -sign
+        }
+        for i := k + 1; i < n; i++ {
+            factor := lu.at(i, k) / lu.at(k, k)
+            lu.set(i, k, factor)
+            for j := k + 1; j < n; j++ {
+                lu.set(i, j, lu.at(i, j) - factor * lu.at(k, j))
+            }
+        }
+    }
+    return lu, perm, sign, nil
+}
+
+// Determinant returns determinant of square matrix, computed from its LU decomposition.
+// Singular matrix results in error
+func (m Matrix) Determinant() (float64, error) {
+    if m.Rows() != m.Cols() {
+        return 0, errors.New("matrices: determinant requires square matrix")
+    }
+    lu, _, sign, err := m.lu()
+    if err != nil {
+        return 0, err
+    }
+    det := sign
+    for i := 0; i < lu.Rows(); i++ {
+        det *= lu.at(i, i)
+    }
+    return det, nil
+}
+
+// Diagonal returns diagonal of square matrix as column vector
+func (m Matrix) Diagonal() (Matrix, error) {
+    if m.Rows() != m.Cols() {
+        return Matrix{}, errors.New("matrices: diagonal requires square matrix")
+    }
+    result := InitMatrix(m.Rows(), 1)
+    for i := range result.values {
+        result.values[i] = m.at(i, i)
+    }
+    return result, nil
+}
+
+// Trace returns sum of diagonal of square matrix
+func (m Matrix) Trace() (float64, error) {
+    if m.Rows() != m.Cols() {
+        return 0, errors.New("matrices: trace requires square matrix")
+    }
+    diagonal, err := m.Diagonal()
+    return diagonal.Sum(), err
+}
+
+// Inverse returns inverse of square matrix, singular matrix results in error
+func (m Matrix) Inverse() (Matrix, error) {
+    lu, perm, _, err := m.lu()
+    if err != nil {
+        return Matrix{}, err
+    }
+    n := m.Rows()
+    inverse := InitMatrix(n, n)
+    column := make([]float64, n)
+    for j := 0; j < n; j++ {
+        // solve L*y = P*e_j by forward substitution
+        for i := 0; i < n; i++ {
+            column[i] = 0
+            if perm[i] == j {
+                column[i] = 1
+            }
+            for k := 0; k < i; k++ {
+                column[i] -= lu.at(i, k) * column[k]
+            }
+        }
+        // solve U*x = y by backward substitution
+        for i := n - 1; i >= 0; i-- {
+            for k := i + 1; k < n; k++ {
+                column[i] -= lu.at(i, k) * column[k]
+            }
+            column[i] /= lu.at(i, i)
+        }
+        for i := 0; i < n; i++ {
+            inverse.set(i, j, column[i])
+        }
+    }
+    return inverse, nil
+}
+
+func (m Matrix) isVector() bool {
+    return m.Rows() == 1 || m.Cols() == 1
+}
+
+// Outer returns outer product of two vectors, element (i, j) of len(m)×len(n) result is i-th element of m
+// times j-th element of n. Both row and column vectors are accepted
+func (m Matrix) Outer(n Matrix) (Matrix, error) {
+    if !m.isVector() || !n.isVector() {
+        return Matrix{}, errors.New("matrices: outer product requires two vectors")
+    }
+    column := InitMatrixWithValues(1, m.values)
+    row := InitMatrixWithValues(len(n.values), n.values)
+    return column.Dot(row)
+}
+
+// Kronecker returns Kronecker product of matrices, block (i, j) of result is element (i, j) of m times n
+func (m Matrix) Kronecker(n Matrix) Matrix {
+    result := InitMatrix(m.Rows() * n.Rows(), m.Cols() * n.Cols())
+    for i := 0; i < m.Rows(); i++ {
+        for j := 0; j < m.Cols(); j++ {
+            val := m.at(i, j)
+            for k := 0; k < n.Rows(); k++ {
+                for l := 0; l < n.Cols(); l++ {
+                    result.set(i * n.Rows() + k, j * n.Cols() + l, val * n.at(k, l))
+                }
+            }
+        }
+    }
+    return result
+}
@@ -0,0 +1,112 @@
+package matrices
+
+import (
+    "math"
+    "testing"
+)
+
+func TestDeterminantAndInverse(t *testing.T) {
+    for _, tc := range []struct {
+        m Matrix
+        determinant float64
+        inverse Matrix
+    }{
+        {
+            InitMatrixWithValues(2, []float64{4, 7, 2, 6}),
+            10,
+            InitMatrixWithValues(2, []float64{0.6, -0.7, -0.2, 0.4}),
+        },
+        {
+            // first row needs pivoting
+            InitMatrixWithValues(2, []float64{0, 1, 2, 3}),
+            -2,
+            InitMatrixWithValues(2, []float64{-1.5, 0.5, 1, 0}),
+        },
+        {
+            InitMatrixWithValues(3, []float64{2, 0, -1, 1, 3, 2, 1, 1, 1}),
+            4,
+            InitMatrixWithValues(3, []float64{0.25, -0.25, 0.75, 0.25, 0.75, -1.25, -0.5, -0.5, 1.5}),
+        },
+    } {
+        determinant, err := tc.m.Determinant()
+        if err != nil || math.Abs(determinant - tc.determinant) > 1e-12 {
+            t.Errorf("determinant of %v = %f, %v, want %f", tc.m, determinant, err, tc.determinant)
+        }
+        inverse, err := tc.m.Inverse()
+        if err != nil || !inverse.Equals(tc.inverse, 1e-12) {
+            t.Errorf("inverse of %v = %v, %v, want %v", tc.m, inverse, err, tc.inverse)
+        }
+        product, err := tc.m.Dot(inverse)
+        if err != nil || !product.Equals(IdentityMatrix(tc.m.Rows()), 1e-12) {
+            t.Errorf("matrix %v times its inverse = %v, %v", tc.m, product, err)
+        }
+    }
+}
+
+func TestSingularMatrix(t *testing.T) {
+    for _, singular := range []Matrix{
+        InitMatrixWithValues(3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9}),
+        InitMatrixWithValues(2, []float64{1, 2, 2, 4}),
+        InitMatrix(2, 2),
+    } {
+        if determinant, err := singular.Determinant(); err == nil {
+            t.Errorf("determinant of singular matrix %v = %g, want error", singular, determinant)
+        }
+        if inverse, err := singular.Inverse(); err == nil {
+            t.Errorf("inverse of singular matrix %v = %v, want error", singular, inverse)
+        }
+    }
+    if _, err := InitMatrix(2, 3).Determinant(); err == nil {
+        t.Error("determinant of non-square matrix did not fail")
+    }
+}
+
+func TestSmallScaleMatrix(t *testing.T) {
+    // tolerance of singularity is relative, so scale of values does not matter
+    small := DiagMatrix([]float64{1e-13, 1e-13})
+    if determinant, err := small.Determinant(); err != nil || math.Abs(determinant - 1e-26) > 1e-38 {
+        t.Errorf("determinant of %v = %g, %v, want 1e-26", small, determinant, err)
+    }
+    want := InitMatrixWithValues(1, []float64{1e13})
+    if inverse, err := InitMatrixWithValues(1, []float64{1e-13}).Inverse(); err != nil || !inverse.Equals(want, 1e-3) {
+        t.Errorf("inverse of [1e-13] = %v, %v, want %v", inverse, err, want)
+    }
+}
+
+func TestOuter(t *testing.T) {
+    row := InitMatrixWithValues(3, []float64{1, 2, 3})
+    column := InitMatrixWithValues(1, []float64{4, 5})
+    want := InitMatrixWithValues(2, []float64{4, 5, 8, 10, 12, 15})
+    for _, m := range []Matrix{row, row.Transpose()} {
+        for _, n := range []Matrix{column, column.Transpose()} {
+            if outer, err := m.Outer(n); err != nil || !outer.EqualExact(want) {
+                t.Errorf("outer product of %v and %v = %v, %v, want %v", m, n, outer, err, want)
+            }
+        }
+    }
+    if _, err := IdentityMatrix(2).Outer(row); err == nil {
+        t.Error("outer product of matrix did not fail")
+    }
+}
+
+func TestKronecker(t *testing.T) {
+    m := InitMatrixWithValues(2, []float64{1, 2, 3, 4})
+    n := InitMatrixWithValues(2, []float64{0, 5, 6, 7})
+    want := InitMatrixWithValues(4, []float64{
+        0, 5, 0, 10,
+        6, 7, 12, 14,
+        0, 15, 0, 20,
+        18, 21, 24, 28,
+    })
+    if kronecker := m.Kronecker(n); !kronecker.EqualExact(want) {
+        t.Errorf("Kronecker product = %v, want %v", kronecker, want)
+    }
+    // product with 1×1 matrix scales other matrix
+    if kronecker := InitMatrixWithValues(1, []float64{2}).Kronecker(m); !kronecker.EqualExact(m.ScalarMult(2)) {
+        t.Errorf("Kronecker product with scalar = %v, want %v", kronecker, m.ScalarMult(2))
+    }
+    row, column := InitMatrixWithValues(2, []float64{1, 2}), InitMatrixWithValues(1, []float64{3, 4})
+    if kronecker := column.Kronecker(row); !kronecker.EqualExact(InitMatrixWithValues(2, []float64{3, 6, 4, 8})) {
+        t.Errorf("Kronecker product of column and row = %v, want their outer product", kronecker)
+    }
+}
@@ -5,7 +5,9 @@ import (
     "math"
     "math/rand"
     "errors"
+    "strconv"
     "strings"
+    "encoding/binary"
     "encoding/json"
 )
 
@@ -17,6 +19,9 @@ type Matrix struct {
 
 // Rows returns number of rows in matrix
 func (m Matrix) Rows() int {
+    if m.cols == 0 {
+        return 0
+    }
     return len(m.values) / m.cols
 }
 
@@ -25,6 +30,12 @@ func (m Matrix) Cols() int {
     return m.cols
 }
 
+// Empty reports whether matrix has no elements. All empty matrices are treated as having the same shape,
+// so element-wise operations on two empty matrices return an empty matrix without error
+func (m Matrix) Empty() bool {
+    return len(m.values) == 0
+}
+
 // InitMatrix initializes Matrix structure to have required number of rows and columns
 func InitMatrix(rows, cols int) Matrix {
     m := Matrix{cols: cols}
@@ -32,24 +43,66 @@ func InitMatrix(rows, cols int) Matrix {
     return m
 }
 
-// RandInitMatrix initializes Matrix structure and fills it with random numbers
-func RandInitMatrix(rows, cols int) Matrix {
+// Zeros creates matrix with given number of rows and columns filled with zeros
+func Zeros(rows, cols int) Matrix {
+    return InitMatrix(rows, cols)
+}
+
+// Ones creates matrix with given number of rows and columns filled with ones
+func Ones(rows, cols int) Matrix {
+    return Full(rows, cols, 1)
+}
+
+// Full creates matrix with given number of rows and columns filled with value v
+func Full(rows, cols int, v float64) Matrix {
     m := InitMatrix(rows, cols)
+    m.Fill(v)
+    return m
+}
+
+// Fill sets every element of matrix to value v
+func (m Matrix) Fill(v float64) {
     for i := range m.values {
-        m.values[i] = rand.NormFloat64()
+        m.values[i] = v
     }
-    return m
 }
 
-// RandInitMatrixNormalized initializes Matrix structure and fills it with random numbers with respect to rows count
-func RandInitMatrixNormalized(rows, cols int) Matrix {
+// RandNormalMatrix initializes Matrix structure and fills it with normally distributed numbers with given
+// standard deviation drawn from r, global source of math/rand is used when r is nil
+func RandNormalMatrix(r *rand.Rand, rows, cols int, std float64) Matrix {
     m := InitMatrix(rows, cols)
     for i := range m.values {
-        m.values[i] = rand.NormFloat64() / math.Sqrt(float64(rows))
+        if r == nil {
+            m.values[i] = rand.NormFloat64() * std
+        } else {
+            m.values[i] = r.NormFloat64() * std
+        }
     }
     return m
 }
 
+// RandInitMatrix initializes Matrix structure and fills it with random numbers
+func RandInitMatrix(rows, cols int) Matrix {
+    return RandNormalMatrix(nil, rows, cols, 1)
+}
+
+// RandInitMatrixNormalized initializes Matrix structure and fills it with random numbers with respect to rows count
+func RandInitMatrixNormalized(rows, cols int) Matrix {
+    return RandNormalMatrix(nil, rows, cols, 1 / math.Sqrt(float64(rows)))
+}
+
+// XavierInitMatrix initializes Matrix structure and fills it with random numbers with standard deviation
+// sqrt(2/(rows+cols)) following Glorot initialization
+func XavierInitMatrix(rows, cols int) Matrix {
+    return RandNormalMatrix(nil, rows, cols, math.Sqrt(2 / float64(rows + cols)))
+}
+
+// HeInitMatrix initializes Matrix structure and fills it with random numbers with standard deviation
+// sqrt(2/rows) suitable for ReLU layers
+func HeInitMatrix(rows, cols int) Matrix {
+    return RandNormalMatrix(nil, rows, cols, math.Sqrt(2 / float64(rows)))
+}
+
 // InitMatrixWithValues initializes Matrix with given dimensions and values
 func InitMatrixWithValues(cols int, values []float64) Matrix {
     return Matrix{cols: cols, values: values}
@@ -62,6 +115,60 @@ func OneHotMatrix(rows, cols, setrow, setcol int) (Matrix, error) {
     return m, err
 }
 
+// IdentityMatrix creates n×n matrix with ones on diagonal and zeros everywhere else
+func IdentityMatrix(n int) Matrix {
+    m := InitMatrix(n, n)
+    for i := 0; i < n; i++ {
+        m.set(i, i, 1)
+    }
+    return m
+}
+
+// DiagMatrix creates square matrix with given values on diagonal and zeros everywhere else
+func DiagMatrix(values []float64) Matrix {
+    m := InitMatrix(len(values), len(values))
+    for i, val := range values {
+        m.set(i, i, val)
+    }
+    return m
+}
+
+// MatrixFrom2D creates matrix from rows of values, all rows must have equal length
+func MatrixFrom2D(data [][]float64) (Matrix, error) {
+    if len(data) == 0 {
+        return Matrix{}, nil
+    }
+    cols := len(data[0])
+    values := make([]float64, 0, len(data) * cols)
+    for _, row := range data {
+        if len(row) != cols {
+            return Matrix{}, errors.New("matrices: all rows must have equal length")
+        }
+        values = append(values, row...)
+    }
+    return InitMatrixWithValues(cols, values), nil
+}
+
+// To2D returns copy of matrix values as slice of rows
+func (m Matrix) To2D() [][]float64 {
+    data := make([][]float64, m.Rows())
+    for i := range data {
+        data[i] = make([]float64, m.cols)
+        copy(data[i], m.values[i * m.cols : (i + 1) * m.cols])
+    }
+    return data
+}
+
+// Reshape returns copy of matrix with the same values in row-major order and new dimensions
+func (m Matrix) Reshape(rows, cols int) (Matrix, error) {
+    if rows < 0 || cols < 0 || rows * cols != len(m.values) {
+        return Matrix{}, errors.New("matrices: reshaped matrix must have the same number of elements")
+    }
+    result := InitMatrix(rows, cols)
+    copy(result.values, m.values)
+    return result, nil
+}
+
 // Copy creates copy of given matrix
 func (m Matrix) Copy() Matrix {
     vals := make([]float64, len(m.values))
@@ -69,6 +176,40 @@ func (m Matrix) Copy() Matrix {
     return InitMatrixWithValues(m.cols, vals)
 }
 
+func (m Matrix) sameShape(n Matrix) bool {
+    if m.Empty() && n.Empty() {
+        return true
+    }
+    return m.cols == n.cols && len(m.values) == len(n.values)
+}
+
+// Equals reports whether matrices have equal dimensions and every pair of their elements differs
+// by less than tolerance
+func (m Matrix) Equals(n Matrix, tolerance float64) bool {
+    if !m.sameShape(n) {
+        return false
+    }
+    for i, val := range m.values {
+        if !(math.Abs(val - n.values[i]) < tolerance) {
+            return false
+        }
+    }
+    return true
+}
+
+// EqualExact reports whether matrices have equal dimensions and equal elements
+func (m Matrix) EqualExact(n Matrix) bool {
+    if !m.sameShape(n) {
+        return false
+    }
+    for i, val := range m.values {
+        if val != n.values[i] {
+            return false
+        }
+    }
+    return true
+}
+
 func (m Matrix) checkRowCol(row, col int) bool {
     return row < m.Rows() && col < m.Cols() && row >= 0 && col >= 0
 }
@@ -98,8 +239,61 @@ func (m Matrix) Set(row, col int, value float64) error {
     return nil
 }
 
+// Row returns copy of i-th row of matrix as 1×cols matrix
+func (m Matrix) Row(i int) (Matrix, error) {
+    if i < 0 || i >= m.Rows() {
+        return Matrix{}, errors.New("matrices: row index outside of matrix")
+    }
+    values := make([]float64, m.cols)
+    copy(values, m.values[i * m.cols : (i + 1) * m.cols])
+    return InitMatrixWithValues(m.cols, values), nil
+}
+
+// Col returns copy of j-th column of matrix as rows×1 matrix
+func (m Matrix) Col(j int) (Matrix, error) {
+    if j < 0 || j >= m.Cols() {
+        return Matrix{}, errors.New("matrices: column index outside of matrix")
+    }
+    result := InitMatrix(m.Rows(), 1)
+    for i := 0; i < m.Rows(); i++ {
+        result.values[i] = m.at(i, j)
+    }
+    return result, nil
+}
+
+func (m Matrix) swapRows(i, j int) {
+    for k := 0; k < m.cols; k++ {
+        m.values[i * m.cols + k], m.values[j * m.cols + k] = m.values[j * m.cols + k], m.values[i * m.cols + k]
+    }
+}
+
+// SwapRows swaps i-th and j-th row of matrix in place
+func (m Matrix) SwapRows(i, j int) error {
+    if i < 0 || i >= m.Rows() || j < 0 || j >= m.Rows() {
+        return errors.New("matrices: row index outside of matrix")
+    }
+    m.swapRows(i, j)
+    return nil
+}
+
+// SwapCols swaps i-th and j-th column of matrix in place
+func (m Matrix) SwapCols(i, j int) error {
+    if i < 0 || i >= m.Cols() || j < 0 || j >= m.Cols() {
+        return errors.New("matrices: column index outside of matrix")
+    }
+    for k := 0; k < m.Rows(); k++ {
+        a, b := m.at(k, i), m.at(k, j)
+        m.set(k, i, b)
+        m.set(k, j, a)
+    }
+    return nil
+}
+
 func (m Matrix) operate(n Matrix, operation func(float64, float64) float64) (Matrix, error) {
     var result Matrix
+    if m.Empty() && n.Empty() {
+        return result, nil
+    }
     if m.Rows() != n.Rows() || m.Cols() != n.Cols() {
         return result, errors.New("matrices: operating on two matrices with different dimensions")
     }
@@ -115,6 +309,38 @@ func (m Matrix) Add(n Matrix) (Matrix, error) {
     return m.operate(n, func (x, y float64) float64 { return x + y; })
 }
 
+// AddBroadcast adds 1×cols row vector to every row of matrix
+func (m Matrix) AddBroadcast(n Matrix) (Matrix, error) {
+    var result Matrix
+    if m.Empty() && n.Empty() {
+        return result, nil
+    }
+    if n.Rows() != 1 || n.Cols() != m.Cols() {
+        return result, errors.New("matrices: broadcast operand must be row vector with the same number of columns")
+    }
+    result = InitMatrix(m.Rows(), m.Cols())
+    for i, val := range m.values {
+        result.values[i] = val + n.values[i % m.cols]
+    }
+    return result, nil
+}
+
+// MultBroadcast multiplies every row of matrix by 1×cols row vector element-wise
+func (m Matrix) MultBroadcast(n Matrix) (Matrix, error) {
+    var result Matrix
+    if m.Empty() && n.Empty() {
+        return result, nil
+    }
+    if n.Rows() != 1 || n.Cols() != m.Cols() {
+        return result, errors.New("matrices: broadcast operand must be row vector with the same number of columns")
+    }
+    result = InitMatrix(m.Rows(), m.Cols())
+    for i, val := range m.values {
+        result.values[i] = val * n.values[i % m.cols]
+    }
+    return result, nil
+}
+
 // Sub subtracts two matrices
 func (m Matrix) Sub(n Matrix) (Matrix, error) {
     return m.operate(n, func (x, y float64) float64 { return x - y; })
@@ -125,6 +351,26 @@ func (m Matrix) Mult(n Matrix) (Matrix, error) {
     return m.operate(n, func (x, y float64) float64 { return x * y; })
 }
 
+// Div divides elements in matrices piecewise, returns error if any element of divisor is zero
+func (m Matrix) Div(n Matrix) (Matrix, error) {
+    for _, val := range n.values {
+        if val == 0 {
+            return Matrix{}, errors.New("matrices: element-wise division by zero")
+        }
+    }
+    return m.operate(n, func (x, y float64) float64 { return x / y; })
+}
+
+// MaxElem returns matrix of element-wise maximums of two matrices
+func (m Matrix) MaxElem(n Matrix) (Matrix, error) {
+    return m.operate(n, math.Max)
+}
+
+// MinElem returns matrix of element-wise minimums of two matrices
+func (m Matrix) MinElem(n Matrix) (Matrix, error) {
+    return m.operate(n, math.Min)
+}
+
 // Apply applies function to each element of Matrix
 func (m Matrix) Apply(operation func(float64) float64) Matrix {
     result := InitMatrix(m.Rows(), m.Cols())
@@ -134,7 +380,75 @@ func (m Matrix) Apply(operation func(float64) float64) Matrix {
     return result
 }
 
-// Sum sumarizes whole matrix
+// operateInPlace stores result of element-wise operation into receiver
+func (m Matrix) operateInPlace(n Matrix, operation func(float64, float64) float64) error {
+    if m.Empty() && n.Empty() {
+        return nil
+    }
+    if m.Rows() != n.Rows() || m.Cols() != n.Cols() {
+        return errors.New("matrices: operating on two matrices with different dimensions")
+    }
+    for i := range m.values {
+        m.values[i] = operation(m.values[i], n.values[i])
+    }
+    return nil
+}
+
+// AddInPlace adds n to matrix element-wise, overwriting values of receiver.
+// It mutates the receiver and every matrix sharing its values, so it must not be used on shared matrices
+func (m Matrix) AddInPlace(n Matrix) error {
+    return m.operateInPlace(n, func (x, y float64) float64 { return x + y; })
+}
+
+// SubInPlace subtracts n from matrix element-wise, overwriting values of receiver.
+// It mutates the receiver and every matrix sharing its values, so it must not be used on shared matrices
+func (m Matrix) SubInPlace(n Matrix) error {
+    return m.operateInPlace(n, func (x, y float64) float64 { return x - y; })
+}
+
+// MultInPlace multiplies matrix by n element-wise, overwriting values of receiver.
+// It mutates the receiver and every matrix sharing its values, so it must not be used on shared matrices
+func (m Matrix) MultInPlace(n Matrix) error {
+    return m.operateInPlace(n, func (x, y float64) float64 { return x * y; })
+}
+
+// ApplyInPlace applies function to each element of matrix, overwriting values of receiver.
+// It mutates the receiver and every matrix sharing its values, so it must not be used on shared matrices
+func (m Matrix) ApplyInPlace(operation func(float64) float64) {
+    for i, val := range m.values {
+        m.values[i] = operation(val)
+    }
+}
+
+// Abs returns Matrix where absolute value was applied to each element
+func (m Matrix) Abs() Matrix {
+    return m.Apply(Abs)
+}
+
+// Pow returns Matrix where each element was raised to given exponent
+func (m Matrix) Pow(exp float64) Matrix {
+    return m.Apply(Pow(exp))
+}
+
+// Clip returns Matrix where every element was clamped into [min, max]
+func (m Matrix) Clip(min, max float64) Matrix {
+    return m.Apply(Clip(min, max))
+}
+
+// ScalarMult multiplies every element of matrix by given scalar
+func (m Matrix) ScalarMult(s float64) Matrix {
+    return m.Apply(Mult(s))
+}
+
+// ScalarDiv divides every element of matrix by given scalar
+func (m Matrix) ScalarDiv(s float64) (Matrix, error) {
+    if s == 0 {
+        return Matrix{}, errors.New("matrices: cannot divide matrix by zero")
+    }
+    return m.Apply(Mult(1 / s)), nil
+}
+
+// Sum sumarizes whole matrix, sum of empty matrix is 0
 func (m Matrix) Sum() float64 {
     sum := 0.0
     for _, val := range m.values {
@@ -143,13 +457,43 @@ func (m Matrix) Sum() float64 {
     return sum
 }
 
+// FrobeniusNorm returns square root of sum of squares of all elements
+func (m Matrix) FrobeniusNorm() float64 {
+    sum := 0.0
+    for _, val := range m.values {
+        sum += val * val
+    }
+    return math.Sqrt(sum)
+}
+
+// L1Norm returns sum of absolute values of all elements
+func (m Matrix) L1Norm() float64 {
+    return m.Abs().Sum()
+}
+
+// L2Norm returns L2 norm of all elements taken as one vector, which equals FrobeniusNorm
+func (m Matrix) L2Norm() float64 {
+    return m.FrobeniusNorm()
+}
+
 // Dot multiplies two matrices
 func (m Matrix) Dot(n Matrix) (Matrix, error) {
-    var result Matrix
     if m.Cols() != n.Rows() {
-        return result, errors.New("matrices: for matrix multiplication, first matrix cols == second matrix rows")
+        return Matrix{}, errors.New("matrices: for matrix multiplication, first matrix cols == second matrix rows")
+    }
+    result := InitMatrix(m.Rows(), n.Cols())
+    return result, m.DotInto(n, result)
+}
+
+// DotInto multiplies two matrices and stores product into result, which must have m.Rows() rows and n.Cols() columns
+// and must not share values with m or n
+func (m Matrix) DotInto(n, result Matrix) error {
+    if m.Cols() != n.Rows() {
+        return errors.New("matrices: for matrix multiplication, first matrix cols == second matrix rows")
+    }
+    if result.Cols() != n.Cols() || len(result.values) != m.Rows() * n.Cols() {
+        return errors.New("matrices: result of matrix multiplication has wrong dimensions")
     }
-    result = InitMatrix(m.Rows(), n.Cols())
     for i := 0; i < result.Rows(); i++ {
         for j := 0; j < result.Cols(); j++ {
             sum := 0.0
@@ -159,6 +503,33 @@ func (m Matrix) Dot(n Matrix) (Matrix, error) {
             result.set(i, j, sum)
         }
     }
+    return nil
+}
+
+// DotCompensated multiplies two matrices like Dot, but accumulates inner sums with Kahan-Babuska compensated
+// summation, which is slower but loses less precision on long dot products
+func (m Matrix) DotCompensated(n Matrix) (Matrix, error) {
+    var result Matrix
+    if m.Cols() != n.Rows() {
+        return result, errors.New("matrices: for matrix multiplication, first matrix cols == second matrix rows")
+    }
+    result = InitMatrix(m.Rows(), n.Cols())
+    for i := 0; i < result.Rows(); i++ {
+        for j := 0; j < result.Cols(); j++ {
+            sum, compensation := 0.0, 0.0
+            for counter := 0; counter < m.Cols(); counter++ {
+                product := m.at(i, counter) * n.at(counter, j)
+                t := sum + product
+                if math.Abs(sum) >= math.Abs(product) {
+                    compensation += (sum - t) + product
+                } else {
+                    compensation += (product - t) + sum
+                }
+                sum = t
+            }
+            result.set(i, j, sum + compensation)
+        }
+    }
     return result, nil
 }
 
@@ -174,15 +545,18 @@ func (m Matrix) Transpose() Matrix {
     return result
 }
 
-// Max returns biggest value in matrix
+// Max returns biggest value in matrix, empty matrix results in error
 func (m Matrix) Max() (float64, error) {
     index, err := m.MaxAt()
-    return m.values[index], err
+    if err != nil {
+        return 0, err
+    }
+    return m.values[index], nil
 }
 
 // MaxAt returns index where biggest value in matrix is
 func (m Matrix) MaxAt() (int, error) {
-    if m.Rows() == 0 || m.Cols() == 0 {
+    if m.Empty() {
         return 0, errors.New("matrices: can't return max value in empty matrix")
     }
     maxval := m.values[0]
@@ -196,15 +570,18 @@ func (m Matrix) MaxAt() (int, error) {
     return maxvalIndex, nil
 }
 
-// Min returns smallest value in matrix
+// Min returns smallest value in matrix, empty matrix results in error
 func (m Matrix) Min() (float64, error) {
     index, err := m.MinAt()
-    return m.values[index], err
+    if err != nil {
+        return 0, err
+    }
+    return m.values[index], nil
 }
 
 // MinAt returns index where smallest value in matrix is
 func (m Matrix) MinAt() (int, error) {
-    if m.Rows() == 0 || m.Cols() == 0 {
+    if m.Empty() {
         return 0, errors.New("matrices: can't return min value in empty matrix")
     }
     minval := m.values[0]
@@ -218,34 +595,138 @@ func (m Matrix) MinAt() (int, error) {
     return minvalIndex, nil
 }
 
-// Sigmoid returns Matrix where Sigmoid function was applied to each element
+// ArgMaxRows returns column index of biggest value in each row, first one wins on ties
+func (m Matrix) ArgMaxRows() []int {
+    result := make([]int, m.Rows())
+    for i := range result {
+        for j := 1; j < m.Cols(); j++ {
+            if m.at(i, j) > m.at(i, result[i]) {
+                result[i] = j
+            }
+        }
+    }
+    return result
+}
+
+// Sigmoid returns Matrix where Sigmoid function was applied to each element, empty matrix stays empty
 func (m Matrix) Sigmoid() Matrix {
     return m.Apply(Negate).Apply(math.Exp).Apply(OnePlus).Apply(Invert)
 }
 
 // SigmoidPrime returns Matrix where SigmoidPrime function was applied to each element
 func (m Matrix) SigmoidPrime() Matrix {
-    result, err := m.Sigmoid().Mult(m.Sigmoid().Apply(OneMinus))
+    return SigmoidPrimeFromActivation(m.Sigmoid())
+}
+
+// SigmoidPrimeFromActivation returns derivative of sigmoid given already computed sigmoid activation a,
+// which is a * (1 - a) and avoids evaluating exponential again
+func SigmoidPrimeFromActivation(a Matrix) Matrix {
+    result, err := a.Mult(a.Apply(OneMinus))
     if err != nil {
         panic(err)
     }
     return result
 }
 
-func (m Matrix) String() (result string) {
-    maxval, err := m.Max()
-    if err != nil {
-        panic(err)
+// ReLU returns Matrix where ReLU function was applied to each element
+func (m Matrix) ReLU() Matrix {
+    return m.Apply(ReLU)
+}
+
+// ReLUPrime returns Matrix where derivative of ReLU was applied to each element
+func (m Matrix) ReLUPrime() Matrix {
+    return m.Apply(ReLUPrime)
+}
+
+// LeakyReLU returns Matrix where leaky ReLU with slope alpha for negative values was applied to each element
+func (m Matrix) LeakyReLU(alpha float64) Matrix {
+    return m.Apply(LeakyReLU(alpha))
+}
+
+// LeakyReLUPrime returns Matrix where derivative of leaky ReLU with slope alpha was applied to each element
+func (m Matrix) LeakyReLUPrime(alpha float64) Matrix {
+    return m.Apply(LeakyReLUPrime(alpha))
+}
+
+// ELU returns Matrix where exponential linear unit with given alpha was applied to each element
+func (m Matrix) ELU(alpha float64) Matrix {
+    return m.Apply(ELU(alpha))
+}
+
+// ELUPrime returns Matrix where derivative of exponential linear unit with given alpha was applied to each element
+func (m Matrix) ELUPrime(alpha float64) Matrix {
+    return m.Apply(ELUPrime(alpha))
+}
+
+// Tanh returns Matrix where hyperbolic tangent was applied to each element
+func (m Matrix) Tanh() Matrix {
+    return m.Apply(math.Tanh)
+}
+
+// TanhPrime returns Matrix where derivative of hyperbolic tangent, 1 - tanh(x)^2, was applied to each element
+func (m Matrix) TanhPrime() Matrix {
+    return m.Tanh().Apply(Square).Apply(OneMinus)
+}
+
+// Softmax returns Matrix where each row was exponentiated and normalized to sum to one,
+// row maximum is subtracted before exponentiation for numerical stability
+func (m Matrix) Softmax() Matrix {
+    result := InitMatrix(m.Rows(), m.Cols())
+    for i := 0; i < m.Rows(); i++ {
+        maxval := math.Inf(-1)
+        for j := 0; j < m.Cols(); j++ {
+            maxval = math.Max(maxval, m.at(i, j))
+        }
+        sum := 0.0
+        for j := 0; j < m.Cols(); j++ {
+            exp := math.Exp(m.at(i, j) - maxval)
+            result.set(i, j, exp)
+            sum += exp
+        }
+        for j := 0; j < m.Cols(); j++ {
+            result.set(i, j, result.at(i, j) / sum)
+        }
+    }
+    return result
+}
+
+// SoftmaxTemp returns softmax of every row of matrix divided by temperature t, t > 1 softens distribution
+// and t < 1 sharpens it. Temperature must be positive
+func (m Matrix) SoftmaxTemp(t float64) (Matrix, error) {
+    if !(t > 0) {
+        return Matrix{}, errors.New("matrices: softmax temperature must be positive")
+    }
+    return m.ScalarMult(1 / t).Softmax(), nil
+}
+
+// String formats matrix as rows of values with two decimal places
+func (m Matrix) String() string {
+    return m.Format(2)
+}
+
+// Format formats matrix as rows of values with given number of decimal places, empty matrix is formatted as []
+func (m Matrix) Format(precision int) (result string) {
+    if m.Empty() {
+        return "[]"
     }
-    flen := numberlen(maxval)
-    floatfmt := fmt.Sprintf("%%%d.2f", flen + 6)
+    if precision < 0 {
+        precision = 0
+    }
+    formatted := make([]string, len(m.values))
+    width := 0
+    for i, val := range m.values {
+        formatted[i] = strconv.FormatFloat(val, 'f', precision, 64)
+        if len(formatted[i]) > width {
+            width = len(formatted[i])
+        }
+    }
+    floatfmt := fmt.Sprintf("%%%ds", width + 2)
     rows := make([]string, m.Rows())
 
     for i := 0; i < m.Rows(); i++ {
         row := ""
         for j := 0; j < m.Cols(); j++ {
-            val := m.at(i, j)
-            row += fmt.Sprintf(floatfmt, val)
+            row += fmt.Sprintf(floatfmt, formatted[i * m.cols + j])
         }
         rows[i] = "| " + row + " |"
     }
@@ -278,3 +759,53 @@ func (m *Matrix) UnmarshalJSON(serialized []byte) error {
     m.values = exportedMatrix.Values
     return nil
 }
+
+// GobEncode implements GobEncoder interface, matrix is encoded as number of columns followed by
+// little-endian IEEE 754 values
+func (m Matrix) GobEncode() ([]byte, error) {
+    serialized := make([]byte, binary.MaxVarintLen64 + 8 * len(m.values))
+    n := binary.PutUvarint(serialized, uint64(m.cols))
+    for _, val := range m.values {
+        binary.LittleEndian.PutUint64(serialized[n:], math.Float64bits(val))
+        n += 8
+    }
+    return serialized[:n], nil
+}
+
+// GobDecode implements GobDecoder interface
+func (m *Matrix) GobDecode(serialized []byte) error {
+    cols, n := binary.Uvarint(serialized)
+    if n <= 0 || (len(serialized) - n) % 8 != 0 {
+        return errors.New("matrices: malformed gob encoded matrix")
+    }
+    serialized = serialized[n:]
+    values := make([]float64, len(serialized) / 8)
+    for i := range values {
+        values[i] = math.Float64frombits(binary.LittleEndian.Uint64(serialized[8 * i:]))
+    }
+    if cols == 0 && len(values) > 0 || cols > 0 && len(values) % int(cols) != 0 {
+        return errors.New("matrices: malformed gob encoded matrix")
+    }
+    m.cols = int(cols)
+    m.values = values
+    return nil
+}
+
+// ClipColumnNorms returns copy of matrix where every column with L2 norm bigger than maxNorm is rescaled to have norm maxNorm
+func (m Matrix) ClipColumnNorms(maxNorm float64) Matrix {
+    result := m.Copy()
+    for j := 0; j < m.Cols(); j++ {
+        norm := 0.0
+        for i := 0; i < m.Rows(); i++ {
+            norm += m.at(i, j) * m.at(i, j)
+        }
+        norm = math.Sqrt(norm)
+        if norm <= maxNorm {
+            continue
+        }
+        for i := 0; i < m.Rows(); i++ {
+            result.set(i, j, m.at(i, j) * maxNorm / norm)
+        }
+    }
+    return result
+}
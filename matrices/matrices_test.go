@@ -0,0 +1,278 @@
+package matrices
+
+import (
+    "math"
+    "math/rand"
+    "testing"
+)
+
+func TestEmptyOperations(t *testing.T) {
+    empties := []Matrix{Matrix{}, InitMatrix(0, 3), InitMatrix(3, 0)}
+    operations := map[string]func(Matrix, Matrix) (Matrix, error) {
+        "Add": Matrix.Add,
+        "Sub": Matrix.Sub,
+        "Mult": Matrix.Mult,
+        "Div": Matrix.Div,
+        "AddBroadcast": Matrix.AddBroadcast,
+        "MultBroadcast": Matrix.MultBroadcast,
+    }
+    for name, operation := range operations {
+        for _, m := range empties {
+            for _, n := range empties {
+                result, err := operation(m, n)
+                if err != nil || !result.Empty() {
+                    t.Errorf("%s of empty matrices = %v, %v, want empty matrix", name, result, err)
+                }
+            }
+        }
+        if _, err := operation(Matrix{}, Ones(1, 2)); err == nil {
+            t.Errorf("%s of empty and non-empty matrix did not fail", name)
+        }
+    }
+}
+
+func TestEmptyReductions(t *testing.T) {
+    var m Matrix
+    if sum := m.Sum(); sum != 0 {
+        t.Errorf("Sum of empty matrix = %f, want 0", sum)
+    }
+    if _, err := m.Max(); err == nil {
+        t.Error("Max of empty matrix did not fail")
+    }
+    if _, err := m.Min(); err == nil {
+        t.Error("Min of empty matrix did not fail")
+    }
+    if _, err := m.MaxAt(); err == nil {
+        t.Error("MaxAt of empty matrix did not fail")
+    }
+    if rows := InitMatrix(0, 3).Rows(); rows != 0 {
+        t.Errorf("Rows of 0×3 matrix = %d, want 0", rows)
+    }
+    if rows := InitMatrix(3, 0).Rows(); rows != 0 {
+        t.Errorf("Rows of 3×0 matrix = %d, want 0", rows)
+    }
+}
+
+func TestEmptyElementWise(t *testing.T) {
+    var m Matrix
+    for name, result := range map[string]Matrix {
+        "Sigmoid": m.Sigmoid(),
+        "SigmoidPrime": m.SigmoidPrime(),
+        "ReLU": m.ReLU(),
+        "Tanh": m.Tanh(),
+        "Softmax": m.Softmax(),
+        "Apply": m.Apply(Negate),
+    } {
+        if !result.Empty() {
+            t.Errorf("%s of empty matrix = %v, want empty matrix", name, result)
+        }
+    }
+    if s := m.String(); s != "[]" {
+        t.Errorf("String of empty matrix = %q, want %q", s, "[]")
+    }
+}
+
+func TestReLU(t *testing.T) {
+    m := InitMatrixWithValues(5, []float64{-2, -0.5, 0, 0.5, 2})
+    if want := InitMatrixWithValues(5, []float64{0, 0, 0, 0.5, 2}); !m.ReLU().EqualExact(want) {
+        t.Errorf("ReLU = %v, want %v", m.ReLU(), want)
+    }
+    if want := InitMatrixWithValues(5, []float64{0, 0, 0, 1, 1}); !m.ReLUPrime().EqualExact(want) {
+        t.Errorf("ReLUPrime = %v, want %v", m.ReLUPrime(), want)
+    }
+}
+
+// numericalDerivative returns central difference approximation of derivative of f at every element of m
+func numericalDerivative(m Matrix, f func(Matrix) Matrix) Matrix {
+    const h = 1e-6
+    forward, err := f(m.Apply(Add(h))).Sub(f(m.Apply(Add(-h))))
+    if err != nil {
+        panic(err)
+    }
+    return forward.ScalarMult(1 / (2 * h))
+}
+
+func TestTanhPrime(t *testing.T) {
+    m := InitMatrixWithValues(5, []float64{-2, -0.5, 0, 0.5, 2})
+    if want := numericalDerivative(m, Matrix.Tanh); !m.TanhPrime().Equals(want, 1e-8) {
+        t.Errorf("TanhPrime = %v, want %v", m.TanhPrime(), want)
+    }
+}
+
+func TestSoftmax(t *testing.T) {
+    m := InitMatrixWithValues(3, []float64{1, 2, 3, -1, 0, 1, 1000, 1001, 1002, -1000, 0, 1000})
+    softmax := m.Softmax()
+    for i := 0; i < softmax.Rows(); i++ {
+        row, err := softmax.Row(i)
+        if err != nil {
+            t.Fatal(err)
+        }
+        if sum := row.Sum(); math.Abs(sum - 1) > 1e-12 {
+            t.Errorf("row %d of softmax %v sums to %f", i, row, sum)
+        }
+        for _, val := range row.values {
+            if math.IsNaN(val) || math.IsInf(val, 0) {
+                t.Errorf("row %d of softmax %v is not finite", i, row)
+            }
+        }
+    }
+    // softmax does not change when constant is added to row
+    first, _ := softmax.Row(0)
+    third, _ := softmax.Row(2)
+    if !first.Equals(third, 1e-12) {
+        t.Errorf("softmax of shifted rows %v and %v differ", first, third)
+    }
+}
+
+func TestNorms(t *testing.T) {
+    // 1 + 4 + 4 + 16 = 25
+    m := InitMatrixWithValues(2, []float64{1, -2, 2, -4})
+    for name, tc := range map[string]struct{ got, want float64 } {
+        "FrobeniusNorm": {m.FrobeniusNorm(), 5},
+        "L2Norm": {m.L2Norm(), 5},
+        "L1Norm": {m.L1Norm(), 9},
+        "FrobeniusNorm of empty matrix": {Matrix{}.FrobeniusNorm(), 0},
+        "L1Norm of empty matrix": {Matrix{}.L1Norm(), 0},
+    } {
+        if math.Abs(tc.got - tc.want) > 1e-12 {
+            t.Errorf("%s = %f, want %f", name, tc.got, tc.want)
+        }
+    }
+}
+
+func BenchmarkSigmoidPrime(b *testing.B) {
+    z := RandNormalMatrix(rand.New(rand.NewSource(1)), 100, 100, 1)
+    a := z.Sigmoid()
+    b.Run("from weighted input", func(b *testing.B) {
+        for i := 0; i < b.N; i++ {
+            z.SigmoidPrime()
+        }
+    })
+    b.Run("from activation", func(b *testing.B) {
+        for i := 0; i < b.N; i++ {
+            SigmoidPrimeFromActivation(a)
+        }
+    })
+}
+
+func TestEquals(t *testing.T) {
+    m := InitMatrixWithValues(2, []float64{1, 2, 3, 4})
+    near := InitMatrixWithValues(2, []float64{1, 2, 3, 4 + 1e-9})
+    for _, tc := range []struct {
+        name string
+        n Matrix
+        equals, exact bool
+    }{
+        {"same values", m.Copy(), true, true},
+        {"values within tolerance", near, true, false},
+        {"different values", InitMatrixWithValues(2, []float64{1, 2, 3, 5}), false, false},
+        {"transposed shape", InitMatrixWithValues(1, []float64{1, 2, 3, 4}), false, false},
+        {"empty", Matrix{}, false, false},
+    } {
+        if got := m.Equals(tc.n, 1e-6); got != tc.equals {
+            t.Errorf("%s: Equals = %t, want %t", tc.name, got, tc.equals)
+        }
+        if got := m.EqualExact(tc.n); got != tc.exact {
+            t.Errorf("%s: EqualExact = %t, want %t", tc.name, got, tc.exact)
+        }
+    }
+    if !(Matrix{}).EqualExact(InitMatrix(0, 3)) {
+        t.Error("empty matrices are not equal")
+    }
+    nan := InitMatrixWithValues(1, []float64{math.NaN()})
+    if nan.Equals(nan, 1) || nan.EqualExact(nan) {
+        t.Error("matrix with NaN equals itself")
+    }
+}
+
+func TestConstructors(t *testing.T) {
+    for name, tc := range map[string]struct {
+        m Matrix
+        want []float64
+    } {
+        "Zeros": {Zeros(2, 3), []float64{0, 0, 0, 0, 0, 0}},
+        "Ones": {Ones(3, 2), []float64{1, 1, 1, 1, 1, 1}},
+        "Full": {Full(2, 2, -1.5), []float64{-1.5, -1.5, -1.5, -1.5}},
+    } {
+        if want := InitMatrixWithValues(tc.m.Cols(), tc.want); !tc.m.EqualExact(want) || len(tc.m.values) != len(tc.want) {
+            t.Errorf("%s = %v, want %v", name, tc.m, want)
+        }
+    }
+    if m := Ones(3, 2); m.Rows() != 3 || m.Cols() != 2 {
+        t.Errorf("Ones(3, 2) is %d×%d", m.Rows(), m.Cols())
+    }
+    m := InitMatrixWithValues(2, []float64{1, 2, 3, 4})
+    view := InitMatrixWithValues(2, m.values)
+    m.Fill(7)
+    if want := Full(2, 2, 7); !m.EqualExact(want) || !view.EqualExact(want) {
+        t.Errorf("Fill(7) = %v, want %v filled in place", m, want)
+    }
+}
+
+func TestLeakyReLUAndELUPrime(t *testing.T) {
+    // derivatives are compared away from kink at zero
+    m := InitMatrixWithValues(6, []float64{-3, -1, -0.1, 0.1, 1, 3})
+    for _, alpha := range []float64{0.01, 0.3} {
+        leaky := func(m Matrix) Matrix { return m.LeakyReLU(alpha) }
+        if want := numericalDerivative(m, leaky); !m.LeakyReLUPrime(alpha).Equals(want, 1e-8) {
+            t.Errorf("LeakyReLUPrime(%g) = %v, want %v", alpha, m.LeakyReLUPrime(alpha), want)
+        }
+        elu := func(m Matrix) Matrix { return m.ELU(alpha) }
+        if want := numericalDerivative(m, elu); !m.ELUPrime(alpha).Equals(want, 1e-8) {
+            t.Errorf("ELUPrime(%g) = %v, want %v", alpha, m.ELUPrime(alpha), want)
+        }
+    }
+}
+
+func TestMatrixFrom2DRoundTrip(t *testing.T) {
+    data := [][]float64{{1, 2, 3}, {4, 5, 6}}
+    m, err := MatrixFrom2D(data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := InitMatrixWithValues(3, []float64{1, 2, 3, 4, 5, 6}); !m.EqualExact(want) {
+        t.Errorf("MatrixFrom2D = %v, want %v", m, want)
+    }
+    back := m.To2D()
+    if len(back) != len(data) {
+        t.Fatalf("To2D returned %d rows, want %d", len(back), len(data))
+    }
+    for i := range data {
+        for j := range data[i] {
+            if back[i][j] != data[i][j] {
+                t.Errorf("To2D()[%d][%d] = %f, want %f", i, j, back[i][j], data[i][j])
+            }
+        }
+    }
+    // rows are copies, so changing them does not change matrix
+    back[0][0] = 100
+    if val, _ := m.At(0, 0); val != 1 {
+        t.Errorf("changing result of To2D changed matrix to %v", m)
+    }
+    if _, err := MatrixFrom2D([][]float64{{1, 2}, {3}}); err == nil {
+        t.Error("MatrixFrom2D of ragged rows did not fail")
+    }
+    if empty, err := MatrixFrom2D(nil); err != nil || !empty.Empty() || len(empty.To2D()) != 0 {
+        t.Errorf("MatrixFrom2D(nil) = %v, %v, want empty matrix", empty, err)
+    }
+}
+
+func TestDotCompensated(t *testing.T) {
+    // terms cancel catastrophically, exact dot product is 2 and naive summation loses both ones
+    m := InitMatrixWithValues(4, []float64{1e16, 1, -1e16, 1})
+    n := Ones(4, 1)
+    naive, err := m.Dot(n)
+    if err != nil {
+        t.Fatal(err)
+    }
+    compensated, err := m.DotCompensated(n)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if val, _ := compensated.At(0, 0); val != 2 {
+        t.Errorf("DotCompensated = %v, want 2", compensated)
+    }
+    if val, _ := naive.At(0, 0); val == 2 {
+        t.Errorf("Dot = %v, example is not ill-conditioned", naive)
+    }
+}
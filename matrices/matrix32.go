@@ -0,0 +1,386 @@
+package matrices
+
+import (
+    "errors"
+    "math"
+    "encoding/binary"
+    "encoding/json"
+)
+
+// Matrix32 is two-dimensional field storing values as float32, so it takes half of memory of Matrix.
+// It is meant for keeping and serving trained networks and offers conversion from and to Matrix and operations
+// needed for inference, training is done on Matrix. Element-wise functions are evaluated and inner sums
+// accumulated in float64, only stored values are rounded
+type Matrix32 struct {
+    cols int
+    values []float32
+}
+
+// InitMatrix32 initializes Matrix32 structure to have required number of rows and columns
+func InitMatrix32(rows, cols int) Matrix32 {
+    return Matrix32{cols: cols, values: make([]float32, rows * cols)}
+}
+
+// InitMatrix32WithValues initializes Matrix32 with given dimensions and values
+func InitMatrix32WithValues(cols int, values []float32) Matrix32 {
+    return Matrix32{cols: cols, values: values}
+}
+
+// Float32 returns copy of matrix with values rounded to float32
+func (m Matrix) Float32() Matrix32 {
+    result := InitMatrix32(m.Rows(), m.Cols())
+    for i, val := range m.values {
+        result.values[i] = float32(val)
+    }
+    return result
+}
+
+// Float64 returns copy of matrix with values converted to float64
+func (m Matrix32) Float64() Matrix {
+    result := InitMatrix(m.Rows(), m.Cols())
+    for i, val := range m.values {
+        result.values[i] = float64(val)
+    }
+    return result
+}
+
+// Rows returns number of rows in matrix
+func (m Matrix32) Rows() int {
+    if m.cols == 0 {
+        return 0
+    }
+    return len(m.values) / m.cols
+}
+
+// Cols returns number of columns in matrix
+func (m Matrix32) Cols() int {
+    return m.cols
+}
+
+// Empty reports whether matrix has no elements
+func (m Matrix32) Empty() bool {
+    return len(m.values) == 0
+}
+
+// Copy creates copy of given matrix
+func (m Matrix32) Copy() Matrix32 {
+    vals := make([]float32, len(m.values))
+    copy(vals, m.values)
+    return InitMatrix32WithValues(m.cols, vals)
+}
+
+// Reshape returns copy of matrix with the same values in row-major order and new dimensions
+func (m Matrix32) Reshape(rows, cols int) (Matrix32, error) {
+    if rows < 0 || cols < 0 || rows * cols != len(m.values) {
+        return Matrix32{}, errors.New("matrices: reshaped matrix must have the same number of elements")
+    }
+    result := InitMatrix32(rows, cols)
+    copy(result.values, m.values)
+    return result, nil
+}
+
+// Row returns copy of i-th row of matrix as 1×cols matrix
+func (m Matrix32) Row(i int) (Matrix32, error) {
+    if i < 0 || i >= m.Rows() {
+        return Matrix32{}, errors.New("matrices: row index outside of matrix")
+    }
+    values := make([]float32, m.cols)
+    copy(values, m.values[i * m.cols : (i + 1) * m.cols])
+    return InitMatrix32WithValues(m.cols, values), nil
+}
+
+// Col returns copy of j-th column of matrix as rows×1 matrix
+func (m Matrix32) Col(j int) (Matrix32, error) {
+    if j < 0 || j >= m.Cols() {
+        return Matrix32{}, errors.New("matrices: column index outside of matrix")
+    }
+    result := InitMatrix32(m.Rows(), 1)
+    for i := 0; i < m.Rows(); i++ {
+        result.values[i] = m.at(i, j)
+    }
+    return result, nil
+}
+
+func (m Matrix32) at(row, col int) float32 {
+    return m.values[row * m.cols + col]
+}
+
+// At returns item that is in matrix at given coordinates
+func (m Matrix32) At(row, col int) (float32, error) {
+    if row < 0 || row >= m.Rows() || col < 0 || col >= m.Cols() {
+        return 0, errors.New("matrices: cannot get value outside of matrix")
+    }
+    return m.at(row, col), nil
+}
+
+// Set sets item in matrix to given value
+func (m Matrix32) Set(row, col int, value float32) error {
+    if row < 0 || row >= m.Rows() || col < 0 || col >= m.Cols() {
+        return errors.New("matrices: cannot set value outside of matrix")
+    }
+    m.values[row * m.cols + col] = value
+    return nil
+}
+
+// Equals reports whether matrices have equal dimensions and every pair of their elements differs
+// by less than tolerance
+func (m Matrix32) Equals(n Matrix32, tolerance float64) bool {
+    if !(m.Empty() && n.Empty()) && (m.cols != n.cols || len(m.values) != len(n.values)) {
+        return false
+    }
+    for i, val := range m.values {
+        if !(math.Abs(float64(val) - float64(n.values[i])) < tolerance) {
+            return false
+        }
+    }
+    return true
+}
+
+func (m Matrix32) operate(n Matrix32, operation func(float64, float64) float64) (Matrix32, error) {
+    var result Matrix32
+    if m.Empty() && n.Empty() {
+        return result, nil
+    }
+    if m.Rows() != n.Rows() || m.Cols() != n.Cols() {
+        return result, errors.New("matrices: operating on two matrices with different dimensions")
+    }
+    result = InitMatrix32(m.Rows(), m.Cols())
+    for i := range m.values {
+        result.values[i] = float32(operation(float64(m.values[i]), float64(n.values[i])))
+    }
+    return result, nil
+}
+
+// Add adds two matrices
+func (m Matrix32) Add(n Matrix32) (Matrix32, error) {
+    return m.operate(n, func (x, y float64) float64 { return x + y; })
+}
+
+// Sub subtracts two matrices
+func (m Matrix32) Sub(n Matrix32) (Matrix32, error) {
+    return m.operate(n, func (x, y float64) float64 { return x - y; })
+}
+
+// Mult multiplies two matrices element-wise
+func (m Matrix32) Mult(n Matrix32) (Matrix32, error) {
+    return m.operate(n, func (x, y float64) float64 { return x * y; })
+}
+
+// Div divides elements in matrices piecewise, returns error if any element of divisor is zero
+func (m Matrix32) Div(n Matrix32) (Matrix32, error) {
+    for _, val := range n.values {
+        if val == 0 {
+            return Matrix32{}, errors.New("matrices: element-wise division by zero")
+        }
+    }
+    return m.operate(n, func (x, y float64) float64 { return x / y; })
+}
+
+// AddBroadcast adds 1×cols row vector to every row of matrix
+func (m Matrix32) AddBroadcast(n Matrix32) (Matrix32, error) {
+    var result Matrix32
+    if m.Empty() && n.Empty() {
+        return result, nil
+    }
+    if n.Rows() != 1 || n.Cols() != m.Cols() {
+        return result, errors.New("matrices: broadcast operand must be row vector with the same number of columns")
+    }
+    result = InitMatrix32(m.Rows(), m.Cols())
+    for i, val := range m.values {
+        result.values[i] = val + n.values[i % m.cols]
+    }
+    return result, nil
+}
+
+// Apply returns Matrix32 where given function was applied to each element, functions of operations.go can be used
+func (m Matrix32) Apply(operation func(float64) float64) Matrix32 {
+    result := InitMatrix32(m.Rows(), m.Cols())
+    for i, val := range m.values {
+        result.values[i] = float32(operation(float64(val)))
+    }
+    return result
+}
+
+// ScalarMult multiplies every element of matrix by s
+func (m Matrix32) ScalarMult(s float64) Matrix32 {
+    return m.Apply(Mult(s))
+}
+
+// Sum returns sum of all elements of matrix
+func (m Matrix32) Sum() float64 {
+    sum := 0.0
+    for _, val := range m.values {
+        sum += float64(val)
+    }
+    return sum
+}
+
+// Abs returns Matrix32 of absolute values of all elements
+func (m Matrix32) Abs() Matrix32 {
+    return m.Apply(Abs)
+}
+
+// FrobeniusNorm returns square root of sum of squares of all elements
+func (m Matrix32) FrobeniusNorm() float64 {
+    sum := 0.0
+    for _, val := range m.values {
+        sum += float64(val) * float64(val)
+    }
+    return math.Sqrt(sum)
+}
+
+// L1Norm returns sum of absolute values of all elements
+func (m Matrix32) L1Norm() float64 {
+    sum := 0.0
+    for _, val := range m.values {
+        sum += math.Abs(float64(val))
+    }
+    return sum
+}
+
+// L2Norm returns L2 norm of all elements taken as one vector, which equals FrobeniusNorm
+func (m Matrix32) L2Norm() float64 {
+    return m.FrobeniusNorm()
+}
+
+// Dot multiplies two matrices
+func (m Matrix32) Dot(n Matrix32) (Matrix32, error) {
+    if m.Cols() != n.Rows() {
+        return Matrix32{}, errors.New("matrices: for matrix multiplication, first matrix cols == second matrix rows")
+    }
+    result := InitMatrix32(m.Rows(), n.Cols())
+    for i := 0; i < result.Rows(); i++ {
+        for j := 0; j < result.Cols(); j++ {
+            sum := 0.0
+            for counter := 0; counter < m.Cols(); counter++ {
+                sum += float64(m.at(i, counter)) * float64(n.at(counter, j))
+            }
+            result.values[i * result.cols + j] = float32(sum)
+        }
+    }
+    return result, nil
+}
+
+// Transpose creates transposed matrix of original matrix
+func (m Matrix32) Transpose() Matrix32 {
+    result := InitMatrix32(m.Cols(), m.Rows())
+    for i := 0; i < m.Rows(); i++ {
+        for j := 0; j < m.Cols(); j++ {
+            result.values[j * result.cols + i] = m.at(i, j)
+        }
+    }
+    return result
+}
+
+// MaxAt returns index where biggest value in matrix is
+func (m Matrix32) MaxAt() (int, error) {
+    if m.Empty() {
+        return 0, errors.New("matrices: can't return max value in empty matrix")
+    }
+    maxvalIndex := 0
+    for i, val := range m.values {
+        if val > m.values[maxvalIndex] {
+            maxvalIndex = i
+        }
+    }
+    return maxvalIndex, nil
+}
+
+// Sigmoid returns Matrix32 where Sigmoid function was applied to each element
+func (m Matrix32) Sigmoid() Matrix32 {
+    return m.Apply(func (f float64) float64 { return 1 / (1 + math.Exp(-f)); })
+}
+
+// ReLU returns Matrix32 where ReLU function was applied to each element
+func (m Matrix32) ReLU() Matrix32 {
+    return m.Apply(ReLU)
+}
+
+// Tanh returns Matrix32 where hyperbolic tangent was applied to each element
+func (m Matrix32) Tanh() Matrix32 {
+    return m.Apply(math.Tanh)
+}
+
+// Softmax returns Matrix32 where each row was exponentiated and normalized to sum to one,
+// row maximum is subtracted before exponentiation for numerical stability
+func (m Matrix32) Softmax() Matrix32 {
+    result := InitMatrix32(m.Rows(), m.Cols())
+    exps := make([]float64, m.Cols())
+    for i := 0; i < m.Rows(); i++ {
+        maxval := math.Inf(-1)
+        for j := 0; j < m.Cols(); j++ {
+            maxval = math.Max(maxval, float64(m.at(i, j)))
+        }
+        sum := 0.0
+        for j := range exps {
+            exps[j] = math.Exp(float64(m.at(i, j)) - maxval)
+            sum += exps[j]
+        }
+        for j, exp := range exps {
+            result.values[i * result.cols + j] = float32(exp / sum)
+        }
+    }
+    return result
+}
+
+// String formats matrix as rows of values with two decimal places
+func (m Matrix32) String() string {
+    return m.Float64().String()
+}
+
+// MarshalJSON implements Marshaler interface
+func (m Matrix32) MarshalJSON() ([]byte, error) {
+    res := struct {
+        Cols int
+        Values []float32
+    }{
+        m.cols,
+        m.values,
+    }
+    return json.Marshal(res)
+}
+
+// UnmarshalJSON implements Unmarshaler interface
+func (m *Matrix32) UnmarshalJSON(serialized []byte) error {
+    var exportedMatrix struct {
+        Cols int
+        Values []float32
+    }
+    if err := json.Unmarshal(serialized, &exportedMatrix); err != nil {
+        return err
+    }
+    m.cols = exportedMatrix.Cols
+    m.values = exportedMatrix.Values
+    return nil
+}
+
+// GobEncode implements GobEncoder interface, matrix is encoded as number of columns followed by
+// little-endian IEEE 754 single precision values
+func (m Matrix32) GobEncode() ([]byte, error) {
+    serialized := make([]byte, binary.MaxVarintLen64 + 4 * len(m.values))
+    n := binary.PutUvarint(serialized, uint64(m.cols))
+    for _, val := range m.values {
+        binary.LittleEndian.PutUint32(serialized[n:], math.Float32bits(val))
+        n += 4
+    }
+    return serialized[:n], nil
+}
+
+// GobDecode implements GobDecoder interface
+func (m *Matrix32) GobDecode(serialized []byte) error {
+    cols, n := binary.Uvarint(serialized)
+    if n <= 0 || (len(serialized) - n) % 4 != 0 {
+        return errors.New("matrices: malformed gob encoded matrix")
+    }
+    serialized = serialized[n:]
+    values := make([]float32, len(serialized) / 4)
+    for i := range values {
+        values[i] = math.Float32frombits(binary.LittleEndian.Uint32(serialized[4 * i:]))
+    }
+    if cols == 0 && len(values) > 0 || cols > 0 && len(values) % int(cols) != 0 {
+        return errors.New("matrices: malformed gob encoded matrix")
+    }
+    m.cols = int(cols)
+    m.values = values
+    return nil
+}
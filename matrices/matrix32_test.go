@@ -0,0 +1,72 @@
+package matrices
+
+import (
+    "math"
+    "math/rand"
+    "testing"
+)
+
+func TestMatrix32MatchesMatrix(t *testing.T) {
+    rng := rand.New(rand.NewSource(1))
+    // values of m are representable in float32, so both types start from the same numbers
+    m := RandNormalMatrix(rng, 3, 4, 1).Float32().Float64()
+    n := RandNormalMatrix(rng, 3, 4, 1).Float32().Float64()
+    m32, n32 := m.Float32(), n.Float32()
+    must := func(m Matrix, err error) Matrix {
+        if err != nil {
+            t.Fatal(err)
+        }
+        return m
+    }
+    must32 := func(m Matrix32, err error) Matrix {
+        if err != nil {
+            t.Fatal(err)
+        }
+        return m.Float64()
+    }
+    for name, tc := range map[string]struct{ got, want Matrix } {
+        "Div": {must32(m32.Div(n32)), must(m.Div(n))},
+        "Reshape": {must32(m32.Reshape(2, 6)), must(m.Reshape(2, 6))},
+        "Row": {must32(m32.Row(1)), must(m.Row(1))},
+        "Col": {must32(m32.Col(2)), must(m.Col(2))},
+        "Abs": {m32.Abs().Float64(), m.Abs()},
+        "ReLU": {m32.ReLU().Float64(), m.ReLU()},
+        "Tanh": {m32.Tanh().Float64(), m.Tanh()},
+        "Softmax": {m32.Softmax().Float64(), m.Softmax()},
+    } {
+        if !tc.got.Equals(tc.want, 1e-6) {
+            t.Errorf("%s = %v, want %v", name, tc.got, tc.want)
+        }
+    }
+    for name, tc := range map[string]struct{ got, want float64 } {
+        "FrobeniusNorm": {m32.FrobeniusNorm(), m.FrobeniusNorm()},
+        "L2Norm": {m32.L2Norm(), m.L2Norm()},
+        "L1Norm": {m32.L1Norm(), m.L1Norm()},
+    } {
+        if math.Abs(tc.got - tc.want) > 1e-12 {
+            t.Errorf("%s = %g, want %g", name, tc.got, tc.want)
+        }
+    }
+}
+
+func TestMatrix32Errors(t *testing.T) {
+    m := InitMatrix32(2, 3)
+    if _, err := m.Reshape(4, 2); err == nil {
+        t.Error("Reshape to different number of elements succeeded")
+    }
+    if _, err := m.Row(2); err == nil {
+        t.Error("Row outside of matrix succeeded")
+    }
+    if _, err := m.Col(-1); err == nil {
+        t.Error("Col outside of matrix succeeded")
+    }
+    if _, err := m.Div(m); err == nil {
+        t.Error("division by zero matrix succeeded")
+    }
+}
+
+func TestMatrix32EmptyBroadcast(t *testing.T) {
+    if result, err := (Matrix32{}).AddBroadcast(Matrix32{}); err != nil || !result.Empty() {
+        t.Errorf("AddBroadcast of empty matrices = %v, %v, want empty matrix", result, err)
+    }
+}
@@ -1,12 +1,6 @@
 package matrices
 
-func numberlen(f float64) (res int) {
-    for f >= 10 {
-        f /= 10
-        res++
-    }
-    return
-}
+import "math"
 
 // Negate negates its argument
 func Negate(f float64) float64 {
@@ -34,6 +28,82 @@ func Mult(f float64) (func (float64) float64) {
 }
 
 // Add returns function that adds given argument
-func Add(f, g float64) (func (float64) float64) {
+func Add(f float64) (func (float64) float64) {
     return func (g float64) float64 { return f + g; }
 }
+
+// Abs returns absolute value of its argument
+func Abs(f float64) float64 {
+    return math.Abs(f)
+}
+
+// Pow returns function that raises its argument to given exponent
+func Pow(exp float64) (func (float64) float64) {
+    return func (f float64) float64 { return math.Pow(f, exp); }
+}
+
+// Clip returns function that clamps its argument into [min, max]
+func Clip(min, max float64) (func (float64) float64) {
+    return func (f float64) float64 { return math.Max(min, math.Min(max, f)); }
+}
+
+// ReLU returns its argument if positive and zero otherwise
+func ReLU(f float64) float64 {
+    if f > 0 {
+        return f
+    }
+    return 0
+}
+
+// ReLUPrime returns derivative of ReLU at its argument
+func ReLUPrime(f float64) float64 {
+    if f > 0 {
+        return 1
+    }
+    return 0
+}
+
+// LeakyReLU returns function that returns its argument if positive and argument multiplied by alpha otherwise
+func LeakyReLU(alpha float64) (func (float64) float64) {
+    return func (f float64) float64 {
+        if f > 0 {
+            return f
+        }
+        return alpha * f
+    }
+}
+
+// LeakyReLUPrime returns function that returns derivative of LeakyReLU with given alpha at its argument
+func LeakyReLUPrime(alpha float64) (func (float64) float64) {
+    return func (f float64) float64 {
+        if f > 0 {
+            return 1
+        }
+        return alpha
+    }
+}
+
+// ELU returns function that returns its argument if positive and alpha * (exp(argument) - 1) otherwise
+func ELU(alpha float64) (func (float64) float64) {
+    return func (f float64) float64 {
+        if f > 0 {
+            return f
+        }
+        return alpha * math.Expm1(f)
+    }
+}
+
+// ELUPrime returns function that returns derivative of ELU with given alpha at its argument
+func ELUPrime(alpha float64) (func (float64) float64) {
+    return func (f float64) float64 {
+        if f > 0 {
+            return 1
+        }
+        return alpha * math.Exp(f)
+    }
+}
+
+// Square squares its argument
+func Square(f float64) float64 {
+    return f * f
+}
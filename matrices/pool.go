@@ -0,0 +1,67 @@
+package matrices
+
+import "sync"
+
+// MatrixPool recycles backing arrays of matrices with equal number of elements,
+// it is safe for concurrent use. Nil pool is valid, it allocates every matrix and discards returned ones
+type MatrixPool struct {
+    mutex sync.RWMutex
+    pools map[int]*sync.Pool
+    // headers recycles pointers to slices, so that neither Get nor Put allocates once pool is warm
+    headers sync.Pool
+}
+
+// NewMatrixPool creates empty matrix pool
+func NewMatrixPool() *MatrixPool {
+    return &MatrixPool{}
+}
+
+func (pool *MatrixPool) sized(size int) *sync.Pool {
+    pool.mutex.RLock()
+    p, ok := pool.pools[size]
+    pool.mutex.RUnlock()
+    if ok {
+        return p
+    }
+    pool.mutex.Lock()
+    defer pool.mutex.Unlock()
+    if p, ok = pool.pools[size]; !ok {
+        if pool.pools == nil {
+            pool.pools = make(map[int]*sync.Pool)
+        }
+        p = &sync.Pool{}
+        pool.pools[size] = p
+    }
+    return p
+}
+
+// Get returns zero matrix with given dimensions, reusing values of previously returned matrix when possible
+func (pool *MatrixPool) Get(rows, cols int) Matrix {
+    if pool == nil {
+        return InitMatrix(rows, cols)
+    }
+    if p, ok := pool.sized(rows * cols).Get().(*[]float64); ok {
+        values := *p
+        *p = nil
+        pool.headers.Put(p)
+        for i := range values {
+            values[i] = 0
+        }
+        return InitMatrixWithValues(cols, values)
+    }
+    return InitMatrix(rows, cols)
+}
+
+// Put returns matrix to pool so its values can be reused by Get.
+// Matrix and every matrix sharing its values must not be used after it was put
+func (pool *MatrixPool) Put(m Matrix) {
+    if pool == nil || m.Empty() {
+        return
+    }
+    p, ok := pool.headers.Get().(*[]float64)
+    if !ok {
+        p = new([]float64)
+    }
+    *p = m.values
+    pool.sized(len(m.values)).Put(p)
+}
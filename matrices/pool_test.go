@@ -0,0 +1,42 @@
+package matrices
+
+import "testing"
+
+func TestPoolReturnsZeroMatrix(t *testing.T) {
+    pool := NewMatrixPool()
+    m := pool.Get(2, 3)
+    m.Fill(7)
+    pool.Put(m)
+    reused := pool.Get(3, 2)
+    if reused.Rows() != 3 || reused.Cols() != 2 || !reused.EqualExact(Zeros(3, 2)) {
+        t.Errorf("Get(3, 2) = %v, want 3×2 zero matrix", reused)
+    }
+}
+
+func TestPoolDoesNotAllocate(t *testing.T) {
+    pool := NewMatrixPool()
+    pool.Put(pool.Get(30, 30))
+    allocs := testing.AllocsPerRun(100, func() {
+        pool.Put(pool.Get(30, 30))
+    })
+    if allocs != 0 {
+        t.Errorf("Get and Put of warm pool made %f allocations, want 0", allocs)
+    }
+}
+
+func BenchmarkPool(b *testing.B) {
+    b.Run("without pool", func(b *testing.B) {
+        b.ReportAllocs()
+        var pool *MatrixPool
+        for i := 0; i < b.N; i++ {
+            pool.Put(pool.Get(30, 30))
+        }
+    })
+    b.Run("with pool", func(b *testing.B) {
+        b.ReportAllocs()
+        pool := NewMatrixPool()
+        for i := 0; i < b.N; i++ {
+            pool.Put(pool.Get(30, 30))
+        }
+    })
+}
@@ -0,0 +1,29 @@
+package matrices
+
+// orthogonalityResidual returns WᵀW - I for given matrix
+func orthogonalityResidual(w Matrix) Matrix {
+    gram, err := w.Transpose().Dot(w)
+    if err != nil {
+        panic(err)
+    }
+    residual, err := gram.Sub(IdentityMatrix(w.Cols()))
+    if err != nil {
+        panic(err)
+    }
+    return residual
+}
+
+// OrthogonalityPenalty returns squared Frobenius norm of WᵀW - I, which is zero for matrix with orthonormal columns
+func OrthogonalityPenalty(w Matrix) float64 {
+    norm := orthogonalityResidual(w).FrobeniusNorm()
+    return norm * norm
+}
+
+// OrthogonalityPenaltyGradient returns gradient of OrthogonalityPenalty with respect to w, that is 4W(WᵀW - I)
+func OrthogonalityPenaltyGradient(w Matrix) Matrix {
+    gradient, err := w.Dot(orthogonalityResidual(w))
+    if err != nil {
+        panic(err)
+    }
+    return gradient.Apply(Mult(4))
+}
@@ -0,0 +1,57 @@
+package matrices
+
+import "errors"
+
+// VStack stacks matrices with equal number of columns on top of each other
+func VStack(matrices ...Matrix) (Matrix, error) {
+    if len(matrices) == 0 {
+        return Matrix{}, nil
+    }
+    cols := matrices[0].Cols()
+    var values []float64
+    for _, m := range matrices {
+        if m.Cols() != cols {
+            return Matrix{}, errors.New("matrices: vertically stacked matrices must have equal number of columns")
+        }
+        values = append(values, m.values...)
+    }
+    return InitMatrixWithValues(cols, values), nil
+}
+
+// HStack places matrices with equal number of rows next to each other
+func HStack(matrices ...Matrix) (Matrix, error) {
+    if len(matrices) == 0 {
+        return Matrix{}, nil
+    }
+    rows := matrices[0].Rows()
+    cols := 0
+    for _, m := range matrices {
+        if m.Rows() != rows {
+            return Matrix{}, errors.New("matrices: horizontally stacked matrices must have equal number of rows")
+        }
+        cols += m.Cols()
+    }
+    result := InitMatrix(rows, cols)
+    offset := 0
+    for _, m := range matrices {
+        for i := 0; i < rows; i++ {
+            for j := 0; j < m.Cols(); j++ {
+                result.set(i, offset + j, m.at(i, j))
+            }
+        }
+        offset += m.Cols()
+    }
+    return result, nil
+}
+
+// Concat joins matrices along given axis, axis 0 stacks them on top of each other like VStack
+// and axis 1 places them next to each other like HStack
+func Concat(axis int, matrices ...Matrix) (Matrix, error) {
+    switch axis {
+    case 0:
+        return VStack(matrices...)
+    case 1:
+        return HStack(matrices...)
+    }
+    return Matrix{}, errors.New("matrices: axis must be 0 or 1")
+}
@@ -0,0 +1,58 @@
+package matrices
+
+import (
+    "errors"
+    "math"
+)
+
+var errEmptyStatistics = errors.New("matrices: can't compute statistics of empty matrix")
+
+// Mean returns arithmetic mean of all elements, empty matrix results in error
+func (m Matrix) Mean() (float64, error) {
+    if m.Empty() {
+        return 0, errEmptyStatistics
+    }
+    return m.Sum() / float64(len(m.values)), nil
+}
+
+// Variance returns population variance of all elements, empty matrix results in error
+func (m Matrix) Variance() (float64, error) {
+    mean, err := m.Mean()
+    if err != nil {
+        return 0, err
+    }
+    sum := 0.0
+    for _, val := range m.values {
+        sum += (val - mean) * (val - mean)
+    }
+    return sum / float64(len(m.values)), nil
+}
+
+// Std returns population standard deviation of all elements, empty matrix results in error
+func (m Matrix) Std() (float64, error) {
+    variance, err := m.Variance()
+    return math.Sqrt(variance), err
+}
+
+// MeanAxis returns means along given axis, axis 0 averages every column into 1×cols matrix
+// and axis 1 averages every row into rows×1 matrix
+func (m Matrix) MeanAxis(axis int) (Matrix, error) {
+    if m.Empty() {
+        return Matrix{}, errEmptyStatistics
+    }
+    switch axis {
+    case 0:
+        result := InitMatrix(1, m.Cols())
+        for i, val := range m.values {
+            result.values[i % m.cols] += val / float64(m.Rows())
+        }
+        return result, nil
+    case 1:
+        result := InitMatrix(m.Rows(), 1)
+        for i, val := range m.values {
+            result.values[i / m.cols] += val / float64(m.Cols())
+        }
+        return result, nil
+    }
+    return Matrix{}, errors.New("matrices: axis must be 0 or 1")
+}
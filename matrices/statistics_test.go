@@ -0,0 +1,50 @@
+package matrices
+
+import (
+    "math"
+    "testing"
+)
+
+func TestStatistics(t *testing.T) {
+    // mean 5, squared deviations 9 + 1 + 1 + 1 + 0 + 0 + 4 + 16 = 32
+    m := InitMatrixWithValues(4, []float64{2, 4, 4, 4, 5, 5, 7, 9})
+    mean, err := m.Mean()
+    if err != nil || mean != 5 {
+        t.Errorf("Mean = %f, %v, want 5", mean, err)
+    }
+    variance, err := m.Variance()
+    if err != nil || math.Abs(variance - 4) > 1e-12 {
+        t.Errorf("Variance = %f, %v, want 4", variance, err)
+    }
+    std, err := m.Std()
+    if err != nil || math.Abs(std - 2) > 1e-12 {
+        t.Errorf("Std = %f, %v, want 2", std, err)
+    }
+    columns, err := m.MeanAxis(0)
+    if want := InitMatrixWithValues(4, []float64{3.5, 4.5, 5.5, 6.5}); err != nil || !columns.Equals(want, 1e-12) {
+        t.Errorf("MeanAxis(0) = %v, %v, want %v", columns, err, want)
+    }
+    rows, err := m.MeanAxis(1)
+    if want := InitMatrixWithValues(1, []float64{3.5, 6.5}); err != nil || !rows.Equals(want, 1e-12) {
+        t.Errorf("MeanAxis(1) = %v, %v, want %v", rows, err, want)
+    }
+    if _, err := m.MeanAxis(2); err == nil {
+        t.Error("MeanAxis(2) did not fail")
+    }
+}
+
+func TestStatisticsOfEmptyMatrix(t *testing.T) {
+    var m Matrix
+    if _, err := m.Mean(); err == nil {
+        t.Error("Mean of empty matrix did not fail")
+    }
+    if _, err := m.Variance(); err == nil {
+        t.Error("Variance of empty matrix did not fail")
+    }
+    if _, err := m.Std(); err == nil {
+        t.Error("Std of empty matrix did not fail")
+    }
+    if _, err := m.MeanAxis(0); err == nil {
+        t.Error("MeanAxis of empty matrix did not fail")
+    }
+}
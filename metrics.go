@@ -0,0 +1,280 @@
+package nn
+
+import (
+	"errors"
+	"math"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// CalibrationBin holds statistics of predictions whose confidence fell into one bin
+type CalibrationBin struct {
+	Lower      float64
+	Upper      float64
+	Count      int
+	Confidence float64
+	Accuracy   float64
+}
+
+// Predict returns predicted class for given input and network output normalized to probabilities
+func (network NN) Predict(input matrices.Matrix) (int, matrices.Matrix) {
+	output := network.FeedForward(input)
+	probabilities := output.Apply(matrices.Mult(1 / output.Sum()))
+	class, err := probabilities.MaxAt()
+	if err != nil {
+		panic(err)
+	}
+	return class, probabilities
+}
+
+// PredictWithThreshold returns predicted class for given input, ok is false when probability
+// of predicted class is below threshold and prediction should be rejected
+func (network NN) PredictWithThreshold(input matrices.Matrix, threshold float64) (class int, ok bool) {
+	class, probabilities := network.Predict(input)
+	confidence, err := probabilities.Max()
+	if err != nil {
+		panic(err)
+	}
+	return class, confidence >= threshold
+}
+
+// EvaluateWithRejection returns ratio of correctly classified inputs among accepted ones and ratio of accepted inputs,
+// predictions with probability below threshold are rejected
+func (network NN) EvaluateWithRejection(inputs []TrainItem, threshold float64) (accuracy, coverage float64) {
+	accepted, correct := 0, 0
+	for _, input := range inputs {
+		class, ok := network.PredictWithThreshold(input.Values, threshold)
+		if !ok {
+			continue
+		}
+		accepted++
+		if float64(class) == input.Label {
+			correct++
+		}
+	}
+	return ratio(correct, accepted), ratio(accepted, len(inputs))
+}
+
+// EvaluateMultiLabel compares outputs of network thresholded at threshold with Target vectors of multi-label inputs
+// thresholded at 0.5, exactMatch is ratio of inputs with all classes right and hamming is ratio of right classes
+func (network NN) EvaluateMultiLabel(inputs []TrainItem, threshold float64) (exactMatch, hamming float64) {
+	matched, right, total := 0, 0, 0
+	for _, input := range inputs {
+		output := network.FeedForward(input.Values)
+		if output.Cols() != input.Target.Cols() {
+			panic(errors.New("nn: multi-label target must have one element per network output"))
+		}
+		wrong := 0
+		for j := 0; j < output.Cols(); j++ {
+			predicted, _ := output.At(0, j)
+			actual, _ := input.Target.At(0, j)
+			if (predicted >= threshold) != (actual >= 0.5) {
+				wrong++
+			}
+		}
+		if wrong == 0 {
+			matched++
+		}
+		right += output.Cols() - wrong
+		total += output.Cols()
+	}
+	return ratio(matched, len(inputs)), ratio(right, total)
+}
+
+// CalibrationBins splits inputs into given number of equally wide confidence bins
+// and returns average confidence and accuracy of each bin
+func (network NN) CalibrationBins(inputs []TrainItem, bins int) []CalibrationBin {
+	if bins <= 0 {
+		panic(errors.New("nn: number of calibration bins must be positive"))
+	}
+	result := make([]CalibrationBin, bins)
+	for i := range result {
+		result[i].Lower = float64(i) / float64(bins)
+		result[i].Upper = float64(i+1) / float64(bins)
+	}
+	for _, input := range inputs {
+		class, probabilities := network.Predict(input.Values)
+		confidence, err := probabilities.Max()
+		if err != nil {
+			panic(err)
+		}
+		index := int(confidence * float64(bins))
+		if index >= bins {
+			index = bins - 1
+		}
+		result[index].Count++
+		result[index].Confidence += confidence
+		if float64(class) == input.Label {
+			result[index].Accuracy++
+		}
+	}
+	for i := range result {
+		if result[i].Count > 0 {
+			result[i].Confidence /= float64(result[i].Count)
+			result[i].Accuracy /= float64(result[i].Count)
+		}
+	}
+	return result
+}
+
+// ExpectedCalibrationError returns weighted average gap between confidence and accuracy over confidence bins
+func (network NN) ExpectedCalibrationError(inputs []TrainItem, bins int) float64 {
+	if len(inputs) == 0 {
+		return 0
+	}
+	ece := 0.0
+	for _, bin := range network.CalibrationBins(inputs, bins) {
+		ece += float64(bin.Count) / float64(len(inputs)) * math.Abs(bin.Accuracy-bin.Confidence)
+	}
+	return ece
+}
+
+// CollectPredictions returns predicted classes and true labels of inputs as parallel slices in input order
+func (network NN) CollectPredictions(inputs []TrainItem) ([]int, []int) {
+	predicted := make([]int, len(inputs))
+	actual := make([]int, len(inputs))
+	for i, input := range inputs {
+		predicted[i] = network.classify(input.Values)
+		actual[i] = int(input.Label)
+	}
+	return predicted, actual
+}
+
+// PredictionAgreement returns fraction of inputs for which both networks predict the same class,
+// networks must have the same input and output dimensions
+func (network NN) PredictionAgreement(other NN, inputs []TrainItem) float64 {
+	if network.layers[0] != other.layers[0] || network.layers[len(network.layers)-1] != other.layers[len(other.layers)-1] {
+		panic(errors.New("nn: compared networks have different input or output dimensions"))
+	}
+	if len(inputs) == 0 {
+		return 1
+	}
+	agree := 0
+	for _, input := range inputs {
+		if network.classify(input.Values) == other.classify(input.Values) {
+			agree++
+		}
+	}
+	return float64(agree) / float64(len(inputs))
+}
+
+// PerSampleLoss returns cost of each input in input order
+func (network NN) PerSampleLoss(inputs []TrainItem) []float64 {
+	losses := make([]float64, len(inputs))
+	for i, input := range inputs {
+		losses[i] = network.itemCost(input)
+	}
+	return losses
+}
+
+// ClassMetrics holds per-class precision, recall and F1 together with their macro and weighted averages
+type ClassMetrics struct {
+	Precision []float64
+	Recall    []float64
+	F1        []float64
+	Support   []int
+
+	// macro averages are plain means over classes
+	MacroPrecision float64
+	MacroRecall    float64
+	MacroF1        float64
+
+	// weighted averages weight every class by its support
+	WeightedPrecision float64
+	WeightedRecall    float64
+	WeightedF1        float64
+}
+
+// Report holds per-class and averaged classification metrics
+type Report struct {
+	ClassMetrics
+	// MicroF1 is F1 of true positives, false positives and false negatives aggregated over classes,
+	// for single-label problems it equals accuracy
+	MicroF1 float64
+}
+
+// Metrics returns precision, recall and F1 of each class with their macro and weighted averages,
+// metrics of classes without predictions or without items are defined as 0
+func (network NN) Metrics(inputs []TrainItem) ClassMetrics {
+	return classMetrics(network.ConfusionMatrix(inputs))
+}
+
+// ClassificationReport returns Metrics of inputs together with micro averaged F1
+func (network NN) ClassificationReport(inputs []TrainItem) Report {
+	confusion := network.ConfusionMatrix(inputs)
+	correct, total := 0, 0
+	for class, row := range confusion {
+		correct += row[class]
+		for _, count := range row {
+			total += count
+		}
+	}
+	// every misclassified item is false positive of one class and false negative of another
+	micro := ratio(correct, total)
+	return Report{classMetrics(confusion), f1(micro, micro)}
+}
+
+func classMetrics(confusion [][]int) ClassMetrics {
+	classes := len(confusion)
+	metrics := ClassMetrics{
+		Precision: make([]float64, classes),
+		Recall:    make([]float64, classes),
+		F1:        make([]float64, classes),
+		Support:   make([]int, classes),
+	}
+	total := 0
+	for class := 0; class < classes; class++ {
+		tp := confusion[class][class]
+		fp, fn := 0, 0
+		for other := 0; other < classes; other++ {
+			if other != class {
+				fp += confusion[other][class]
+				fn += confusion[class][other]
+			}
+		}
+		metrics.Support[class] = tp + fn
+		metrics.Precision[class] = ratio(tp, tp+fp)
+		metrics.Recall[class] = ratio(tp, tp+fn)
+		metrics.F1[class] = f1(metrics.Precision[class], metrics.Recall[class])
+		total += metrics.Support[class]
+	}
+	for class := 0; class < classes; class++ {
+		metrics.MacroPrecision += metrics.Precision[class] / float64(classes)
+		metrics.MacroRecall += metrics.Recall[class] / float64(classes)
+		metrics.MacroF1 += metrics.F1[class] / float64(classes)
+		weight := ratio(metrics.Support[class], total)
+		metrics.WeightedPrecision += metrics.Precision[class] * weight
+		metrics.WeightedRecall += metrics.Recall[class] * weight
+		metrics.WeightedF1 += metrics.F1[class] * weight
+	}
+	return metrics
+}
+
+// ConfusionMatrix returns counts of classified inputs as classes × classes matrix, where row is actual class
+// (Label of item) and column is class predicted by network, so element [i][j] counts items of class i predicted as j
+func (network NN) ConfusionMatrix(inputs []TrainItem) [][]int {
+	classes := network.layers[len(network.layers)-1]
+	counts := make([][]int, classes)
+	for i := range counts {
+		counts[i] = make([]int, classes)
+	}
+	predicted, actual := network.CollectPredictions(inputs)
+	for i := range predicted {
+		counts[actual[i]][predicted[i]]++
+	}
+	return counts
+}
+
+func ratio(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+func f1(precision, recall float64) float64 {
+	if precision+recall == 0 {
+		return 0
+	}
+	return 2 * precision * recall / (precision + recall)
+}
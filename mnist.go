@@ -0,0 +1,89 @@
+package nn
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// LoadMNIST loads MNIST images and labels from files in IDX format, plain or gzip-compressed.
+// Every image is flattened into 1×(rows*cols) matrix of pixels normalized to [0,1]
+func LoadMNIST(imagesPath, labelsPath string) ([]TrainItem, error) {
+	imageDims, pixels, err := readIDX(imagesPath, 3)
+	if err != nil {
+		return nil, err
+	}
+	labelDims, labels, err := readIDX(labelsPath, 1)
+	if err != nil {
+		return nil, err
+	}
+	if imageDims[0] != labelDims[0] {
+		return nil, fmt.Errorf("nn: mnist: %d images but %d labels", imageDims[0], labelDims[0])
+	}
+
+	size := imageDims[1] * imageDims[2]
+	items := make([]TrainItem, imageDims[0])
+	for i := range items {
+		if labels[i] > 9 {
+			return nil, fmt.Errorf("nn: mnist: invalid label %d of item %d", labels[i], i)
+		}
+		values := make([]float64, size)
+		for j, pixel := range pixels[i*size : (i+1)*size] {
+			values[j] = float64(pixel) / 255
+		}
+		items[i] = InitTrainItem(values, float64(labels[i]), 10)
+	}
+	return items, nil
+}
+
+// readIDX reads IDX file of unsigned bytes with given number of dimensions and returns its dimensions and data,
+// gzip-compressed file is recognized by its magic bytes
+func readIDX(path string, dimensions int) ([]int, []byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	buffered := bufio.NewReader(f)
+	var r io.Reader = buffered
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, nil, fmt.Errorf("nn: idx %s: cannot read header: %v", path, err)
+	}
+	// magic number is two zero bytes, type code 0x08 for unsigned bytes and number of dimensions
+	if header[0] != 0 || header[1] != 0 || header[2] != 0x08 || int(header[3]) != dimensions {
+		return nil, nil, fmt.Errorf("nn: idx %s: expected unsigned byte data with %d dimensions", path, dimensions)
+	}
+	dims := make([]int, dimensions)
+	size := 1
+	for i := range dims {
+		var dim uint32
+		if err := binary.Read(r, binary.BigEndian, &dim); err != nil {
+			return nil, nil, fmt.Errorf("nn: idx %s: cannot read dimensions: %v", path, err)
+		}
+		dims[i] = int(dim)
+		size *= dims[i]
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, int64(size)))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(data) != size {
+		return nil, nil, fmt.Errorf("nn: idx %s: expected %d bytes of data, got %d", path, size, len(data))
+	}
+	return dims, data, nil
+}
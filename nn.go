@@ -1,52 +1,193 @@
 package nn
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
 	"math/rand"
 	"os"
+	"sync"
 
 	"github.com/tek-shinobi/back-propagation-nn/matrices"
 )
 
 // NN represents neural network to be used with backpropagation
 type NN struct {
-	layers  []int
-	weights []matrices.Matrix
-	biases  []matrices.Matrix
+	layers       []int
+	weights      []matrices.Matrix
+	biases       []matrices.Matrix
+	clamps       []float64
+	activations  []Activation
+	targetScaler *TargetScaler
+	cost         CostFunction
+	// costEpsilon bounds outputs before logarithm of cross-entropy, defaultCostEpsilon is used when it is zero
+	costEpsilon  float64
+	dropout      []float64
+	batchNorm    []*BatchNorm
+	classWeights []float64
+	// optimizer is default optimizer of training, copies of network get own copy of its state
+	optimizer Optimizer
+	// compensatedDot makes forward pass use compensated summation of weighted inputs
+	compensatedDot bool
 }
 
-// InitNN creates new neural network with given number of layers, neurons in each layer and initalizes them randomly
-func InitNN(layers []int) NN {
+// InitNN creates new neural network with given number of layers, neurons in each layer and initalizes them randomly.
+// Optionally activation of each layer transition can be given, sigmoid is used for all layers otherwise.
+// Softmax can only be activation of output layer and network with it uses CategoricalCrossEntropy cost
+func InitNN(layers []int, activations ...Activation) NN {
+	return InitNNWithInitializer(layers, NormalizedInit, activations...)
+}
+
+// InitNNWithInitializer creates new neural network like InitNN with weights initialized by given strategy
+func InitNNWithInitializer(layers []int, initializer Initializer, activations ...Activation) NN {
+	return InitNNWithRand(layers, nil, initializer, activations...)
+}
+
+// InitNNWithRand creates new neural network like InitNNWithInitializer drawing all random numbers from rng,
+// so networks created from equally seeded sources are identical
+func InitNNWithRand(layers []int, rng *rand.Rand, initializer Initializer, activations ...Activation) NN {
+	if len(activations) != 0 && len(activations) != len(layers)-1 {
+		panic(errors.New("nn: number of activations must match number of layer transitions"))
+	}
+	if err := checkSoftmax(activations); err != nil {
+		panic(err)
+	}
 	biases := make([]matrices.Matrix, len(layers)-1)
 	weights := make([]matrices.Matrix, len(layers)-1)
 
 	for i := range layers[1:] {
-		biases[i] = matrices.RandInitMatrix(1, layers[i+1])
+		biases[i] = matrices.RandNormalMatrix(rng, 1, layers[i+1], 1)
 	}
 
 	for i := range layers[1:] {
-		weights[i] = matrices.RandInitMatrixNormalized(layers[i], layers[i+1])
+		weights[i] = initializer.matrix(layers[i], layers[i+1], rng)
 	}
 
-	return NN{layers, weights, biases}
+	network := NN{layers: layers, weights: weights, biases: biases}
+	if len(activations) > 0 {
+		network.activations = make([]Activation, len(activations))
+		copy(network.activations, activations)
+		if isSoftmax(activations[len(activations)-1]) {
+			network.cost = CategoricalCrossEntropy
+		}
+	}
+	return network
 }
 
 // Copy creates copy if given network
 func (network NN) Copy() NN {
 	layers := make([]int, len(network.layers))
 	copy(layers, network.layers)
-	biases := make([]matrices.Matrix, len(network.biases))
-	for i, bias := range network.biases {
-		biases[i] = bias.Copy()
+	biases := copyMatrices(network.biases)
+	weights := copyMatrices(network.weights)
+	var clamps []float64
+	if network.clamps != nil {
+		clamps = make([]float64, len(network.clamps))
+		copy(clamps, network.clamps)
+	}
+	var activations []Activation
+	if network.activations != nil {
+		activations = make([]Activation, len(network.activations))
+		copy(activations, network.activations)
 	}
-	weights := make([]matrices.Matrix, len(network.weights))
-	for i, weight := range network.weights {
-		weights[i] = weight.Copy()
+	var dropout []float64
+	if network.dropout != nil {
+		dropout = make([]float64, len(network.dropout))
+		copy(dropout, network.dropout)
 	}
-	return NN{layers, biases, weights}
+	var batchNorm []*BatchNorm
+	if network.batchNorm != nil {
+		batchNorm = make([]*BatchNorm, len(network.batchNorm))
+		for i, norm := range network.batchNorm {
+			if norm != nil {
+				batchNorm[i] = norm.Copy()
+			}
+		}
+	}
+	var classWeights []float64
+	if network.classWeights != nil {
+		classWeights = make([]float64, len(network.classWeights))
+		copy(classWeights, network.classWeights)
+	}
+	var targetScaler *TargetScaler
+	if network.targetScaler != nil {
+		copied := network.targetScaler.Copy()
+		targetScaler = &copied
+	}
+	return NN{
+		layers:         layers,
+		weights:        weights,
+		biases:         biases,
+		clamps:         clamps,
+		activations:    activations,
+		targetScaler:   targetScaler,
+		cost:           network.cost,
+		costEpsilon:    network.costEpsilon,
+		dropout:        dropout,
+		batchNorm:      batchNorm,
+		classWeights:   classWeights,
+		optimizer:      copyOptimizer(network.optimizer),
+		compensatedDot: network.compensatedDot,
+	}
+}
+
+// copyMatrices returns deep copies of given matrices
+func copyMatrices(mats []matrices.Matrix) []matrices.Matrix {
+	copies := make([]matrices.Matrix, len(mats))
+	for i, m := range mats {
+		copies[i] = m.Copy()
+	}
+	return copies
+}
+
+// Layers returns copy of sizes of network layers, from input to output layer
+func (network NN) Layers() []int {
+	layers := make([]int, len(network.layers))
+	copy(layers, network.layers)
+	return layers
+}
+
+// Weights returns copies of weight matrices, matrix i has layers[i] rows and layers[i+1] columns
+func (network NN) Weights() []matrices.Matrix {
+	return copyMatrices(network.weights)
+}
+
+// Biases returns copies of bias row vectors, vector i has layers[i+1] columns
+func (network NN) Biases() []matrices.Matrix {
+	return copyMatrices(network.biases)
+}
+
+// SetWeights replaces weights of network by copies of given matrices,
+// matrix i must have layers[i] rows and layers[i+1] columns
+func (network *NN) SetWeights(weights []matrices.Matrix) error {
+	if len(weights) != len(network.layers)-1 {
+		return fmt.Errorf("nn: network with %d layers needs %d weight matrices, got %d", len(network.layers), len(network.layers)-1, len(weights))
+	}
+	for i, weight := range weights {
+		if weight.Rows() != network.layers[i] || weight.Cols() != network.layers[i+1] {
+			return fmt.Errorf("nn: weight matrix %d must be %d×%d, got %d×%d", i, network.layers[i], network.layers[i+1], weight.Rows(), weight.Cols())
+		}
+	}
+	network.weights = copyMatrices(weights)
+	return nil
+}
+
+// SetBiases replaces biases of network by copies of given row vectors, vector i must have layers[i+1] columns
+func (network *NN) SetBiases(biases []matrices.Matrix) error {
+	if len(biases) != len(network.layers)-1 {
+		return fmt.Errorf("nn: network with %d layers needs %d bias vectors, got %d", len(network.layers), len(network.layers)-1, len(biases))
+	}
+	for i, bias := range biases {
+		if bias.Rows() != 1 || bias.Cols() != network.layers[i+1] {
+			return fmt.Errorf("nn: bias vector %d must be 1×%d, got %d×%d", i, network.layers[i+1], bias.Rows(), bias.Cols())
+		}
+	}
+	network.biases = copyMatrices(biases)
+	return nil
 }
 
 func (network NN) String() (result string) {
@@ -65,73 +206,243 @@ func (network NN) String() (result string) {
 	return
 }
 
-// FeedForward returns output of given Network on given input
+// FeedForward returns output of given Network on given input, it panics when input does not fit the network.
+// Use FeedForwardErr to handle bad input without panic
 func (network NN) FeedForward(input matrices.Matrix) matrices.Matrix {
-	lastOutput := input
+	output, err := network.FeedForwardErr(input)
+	if err != nil {
+		panic(err)
+	}
+	return output
+}
+
+// FeedForwardErr returns output of given Network on given input or error when input dimensions do not fit the network
+func (network NN) FeedForwardErr(input matrices.Matrix) (matrices.Matrix, error) {
+	activations, err := network.Activations(input)
+	if err != nil {
+		return matrices.Matrix{}, err
+	}
+	return activations[len(activations)-1], nil
+}
+
+// Activations returns activation of every layer for given input like backprop computes them, first one is input itself
+// and last one is output of network. Error is returned when input dimensions do not fit the network
+func (network NN) Activations(input matrices.Matrix) ([]matrices.Matrix, error) {
+	activations := make([]matrices.Matrix, len(network.layers))
+	activations[0] = input
 	for i := range network.weights {
-		weights := network.weights[i]
-		biases := network.biases[i]
-		multiplied, err := lastOutput.Dot(weights)
+		z, err := network.preActivation(i, activations[i])
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
-		added, err := multiplied.Add(biases)
-		if err != nil {
-			panic(err)
+		if norm := network.norm(i); norm != nil {
+			z, _ = norm.forward(z, false)
+		}
+		activations[i+1] = network.activation(i).Apply(z)
+	}
+	return activations, nil
+}
+
+// preActivation returns weighted input of layer transition i for given activation of previous layer,
+// clamped into [-c, c] when clamp c is set for that transition
+func (network NN) preActivation(i int, activation matrices.Matrix) (matrices.Matrix, error) {
+	dot := activation.Dot
+	if network.compensatedDot {
+		dot = activation.DotCompensated
+	}
+	multiplied, err := dot(network.weights[i])
+	if err != nil {
+		return multiplied, err
+	}
+	z, err := multiplied.AddBroadcast(network.biases[i])
+	if err != nil {
+		return z, err
+	}
+	if i < len(network.clamps) && network.clamps[i] > 0 {
+		c := network.clamps[i]
+		z = z.Clip(-c, c)
+	}
+	return z, nil
+}
+
+// clampMask returns matrix of ones where weighted input z of transition i lies inside its clamp and zeros
+// where it was clamped, empty matrix is returned when transition has no clamp
+func (network NN) clampMask(i int, z matrices.Matrix) matrices.Matrix {
+	if i >= len(network.clamps) || network.clamps[i] <= 0 {
+		return matrices.Matrix{}
+	}
+	c := network.clamps[i]
+	return z.Apply(func(f float64) float64 {
+		if math.Abs(f) < c {
+			return 1
 		}
-		lastOutput = added.Sigmoid()
+		return 0
+	})
+}
+
+// SetPreActivationClamp sets per-layer clamp of weighted inputs into [-c, c] to prevent saturation,
+// one value for each layer transition, 0 disables clamp for given transition. Clamped weighted inputs
+// do not depend on weights, so they pass no gradient back during training
+func (network *NN) SetPreActivationClamp(clamps []float64) error {
+	if len(clamps) != len(network.weights) {
+		return errors.New("nn: number of clamps must match number of layer transitions")
 	}
-	return lastOutput
+	network.clamps = make([]float64, len(clamps))
+	copy(network.clamps, clamps)
+	return nil
+}
+
+// SetCompensatedDot makes weighted inputs of forward pass, both in prediction and training, summed
+// with compensated summation, which is slower but more accurate for wide layers. Gradients are not affected
+func (network *NN) SetCompensatedDot(enabled bool) {
+	network.compensatedDot = enabled
 }
 
 // Evaluate returns ratio of correctly clasified inputs
 func (network NN) Evaluate(inputs []TrainItem) float64 {
+	if len(inputs) == 0 {
+		return math.NaN()
+	}
+	values := make([]matrices.Matrix, len(inputs))
+	for i, input := range inputs {
+		values[i] = input.Values
+	}
+	x, err := matrices.VStack(values...)
+	if err != nil {
+		panic(err)
+	}
 	correct := 0
-	for _, input := range inputs {
-		output := network.FeedForward(input.Values)
-		max, err := output.MaxAt()
-		if err != nil {
-			panic(err)
-		}
-		if float64(max) == input.Label {
+	for i, class := range network.FeedForward(x).ArgMaxRows() {
+		if float64(class) == inputs[i].Label {
 			correct++
 		}
 	}
 	return float64(correct) / float64(len(inputs))
 }
 
-// Cost returns total cost of input training items for cross-entropy
+// classifies reports whether network predicts label of given item
+func (network NN) classifies(item TrainItem) bool {
+	return float64(network.classify(item.Values)) == item.Label
+}
+
+// classify returns index of strongest output of network for given input
+func (network NN) classify(input matrices.Matrix) int {
+	output := network.FeedForward(input)
+	max, err := output.MaxAt()
+	if err != nil {
+		panic(err)
+	}
+	return max
+}
+
+// Cost returns average cost of input training items, cross-entropy uses natural logarithm
 func (network NN) Cost(inputs []TrainItem) float64 {
 	cost := 0.0
 	for _, input := range inputs {
-		output := network.FeedForward(input.Values)
-		y, err := matrices.OneHotMatrix(1, input.Distinct, 0, int(input.Label))
-		if err != nil {
-			panic(err)
-		}
-		first, err := y.Apply(matrices.Negate).Mult(output.Apply(math.Log2))
-		if err != nil {
-			panic(err)
-		}
-		second, err := y.Apply(matrices.OneMinus).Mult(output.Apply(matrices.OneMinus).Apply(math.Log2))
+		cost += network.itemCost(input)
+	}
+	return cost / float64(len(inputs))
+}
+
+// itemCost returns cost of single training item
+func (network NN) itemCost(item TrainItem) float64 {
+	y, err := network.target(item)
+	if err != nil {
+		panic(err)
+	}
+	if network.cost == MeanSquaredError {
+		diff, err := network.FeedForward(item.Values).Sub(y)
 		if err != nil {
 			panic(err)
 		}
-		together, err := first.Sub(second)
+		return 0.5 * diff.Apply(matrices.Square).Sum() * network.itemWeight(item)
+	}
+
+	epsilon := network.epsilon()
+	output := network.FeedForward(item.Values).Clip(epsilon, 1-epsilon)
+	if network.cost == CategoricalCrossEntropy {
+		product, err := y.Mult(output.Apply(math.Log))
 		if err != nil {
 			panic(err)
 		}
-		cost += together.Sum()
+		return -product.Sum() * network.itemWeight(item)
 	}
-	return cost / float64(len(inputs))
+	first, err := y.Apply(matrices.Negate).Mult(output.Apply(math.Log))
+	if err != nil {
+		panic(err)
+	}
+	second, err := y.Apply(matrices.OneMinus).Mult(output.Apply(matrices.OneMinus).Apply(math.Log))
+	if err != nil {
+		panic(err)
+	}
+	together, err := first.Sub(second)
+	if err != nil {
+		panic(err)
+	}
+	return together.Sum() * network.itemWeight(item)
 }
 
-// Train trains Network on given input with given settings
-func (network NN) Train(inputs []TrainItem, epochs, miniBatchSize int, eta, etaFraction, lmbda float64, testData []TrainItem, printCost bool) {
-	oldEta := eta
-	inputCount := len(inputs)
+// Train trains Network on given input with given settings, prints progress of every epoch and returns recorded History,
+// which holds training cost only with RecordTrainingCost option.
+// It panics before training starts when settings or inputs are invalid, e.g. mini-batch size is not positive,
+// TrainWithError returns error instead
+func (network NN) Train(inputs []TrainItem, epochs, miniBatchSize int, eta, etaFraction, lmbda float64, testData []TrainItem, printCost bool, opts ...TrainOption) History {
+	history, err := network.TrainWithError(inputs, epochs, miniBatchSize, eta, etaFraction, lmbda, testData, printCost, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return history
+}
+
+// TrainWithError trains Network like Train, but invalid settings or inputs result in descriptive error
+// returned before training starts instead of panic
+func (network NN) TrainWithError(inputs []TrainItem, epochs, miniBatchSize int, eta, etaFraction, lmbda float64, testData []TrainItem, printCost bool, opts ...TrainOption) (History, error) {
+	opts = append([]TrainOption{skipTrainingCost()}, opts...)
+	return network.train(context.Background(), inputs, trainConfig(epochs, miniBatchSize, eta, etaFraction, lmbda, testData, printCost, opts), writerLogger{os.Stdout})
+}
+
+// TrainWithHistory trains Network like Train, but instead of printing progress only returns it in History,
+// progress is still logged when LogTo option is given
+func (network NN) TrainWithHistory(inputs []TrainItem, epochs, miniBatchSize int, eta, etaFraction, lmbda float64, testData []TrainItem, opts ...TrainOption) History {
+	history, err := network.train(context.Background(), inputs, trainConfig(epochs, miniBatchSize, eta, etaFraction, lmbda, testData, false, opts), nil)
+	if err != nil {
+		panic(err)
+	}
+	return history
+}
+
+// TrainContext trains Network like TrainWithHistory, but stops when ctx is done and returns ctx.Err().
+// Context is checked between mini-batches and network is then left with weights, batch normalization and
+// optimizer state of last completed epoch, state of optimizers other than those of this package is kept as it is.
+// Invalid settings or inputs result in error instead of panic
+func (network NN) TrainContext(ctx context.Context, inputs []TrainItem, epochs, miniBatchSize int, eta, etaFraction, lmbda float64, testData []TrainItem, opts ...TrainOption) (History, error) {
+	return network.train(ctx, inputs, trainConfig(epochs, miniBatchSize, eta, etaFraction, lmbda, testData, false, opts), nil)
+}
+
+func (network NN) train(ctx context.Context, inputs []TrainItem, config TrainConfig, logger Logger) (history History, err error) {
+	if err := config.validate(inputs); err != nil {
+		return history, err
+	}
+	for _, items := range [][]TrainItem{inputs, config.TestData} {
+		if err := ValidateDataset(items, network); err != nil {
+			return history, err
+		}
+	}
+	if err := checkSoftmax(network.activations); err != nil {
+		return history, err
+	}
+	if len(network.activations) > 0 && isSoftmax(network.activations[len(network.activations)-1]) && network.cost != CategoricalCrossEntropy {
+		return history, errors.New("nn: softmax output requires CategoricalCrossEntropy cost")
+	}
+	defaults := []TrainOption{LogTo(logger)}
+	if network.optimizer != nil {
+		defaults = append(defaults, UseOptimizer(network.optimizer))
+	}
+	options := newTrainOptions(append(defaults, config.Options...))
+	epochs, miniBatchSize, eta, etaFraction, lmbda := config.Epochs, config.MiniBatchSize, config.Eta, config.EtaFraction, config.Lambda
+	testData, printCost := config.TestData, config.PrintCost
 	i := 0
-	doingBestOfN := false
+	doingBestOfN := config.BestOfN
 	if epochs < 0 {
 		doingBestOfN = true
 		epochs = -epochs
@@ -139,41 +450,65 @@ func (network NN) Train(inputs []TrainItem, epochs, miniBatchSize int, eta, etaF
 	bestCost := network.Cost(testData)
 	bestNetwork := network.Copy()
 	bestBefore := 0
+	// best-of-N halves learning rate on plateau while EtaFraction allows it
+	var plateau *ReduceOnPlateau
+	if doingBestOfN && etaFraction > 0 {
+		plateau = &ReduceOnPlateau{Patience: epochs, Factor: 0.5, MinDelta: options.earlyStopping.minDelta(), MinEta: eta / etaFraction}
+		plateau.ObserveCost(bestCost)
+	}
+	updates := 0
 	for {
 		if !doingBestOfN && i >= epochs {
 			break
 		} else if doingBestOfN && bestBefore >= epochs {
-			if etaFraction > 0 && eta*etaFraction > oldEta {
-				bestBefore = 0
-				eta /= 2.0
-			} else {
-				network = bestNetwork
+			reduced := eta
+			if plateau != nil {
+				reduced = plateau.LearningRate(i, eta)
+			}
+			if reduced == eta {
+				network.restore(bestNetwork)
 				break
 			}
+			bestBefore = 0
+			eta = reduced
 		}
-		shuffled := make([]TrainItem, inputCount)
-		perm := rand.Perm(inputCount)
-		for i, v := range perm {
-			shuffled[v] = inputs[i]
+		if err := ctx.Err(); err != nil {
+			return history, err
 		}
-
-		batchesCount := int(float64(inputCount)/float64(miniBatchSize) + 0.5)
-		batches := make([][]TrainItem, batchesCount)
-		for i := 0; i < batchesCount; i++ {
-			if i+miniBatchSize >= inputCount {
-				batches[i] = shuffled[i*miniBatchSize:]
-			} else {
-				batches[i] = shuffled[i*miniBatchSize : i*miniBatchSize+miniBatchSize]
-			}
+		// weights of last completed epoch are kept only when training can be cancelled
+		var epochStart NN
+		var optimizerStart Optimizer
+		if ctx.Done() != nil {
+			epochStart = network.Copy()
+			optimizerStart = copyOptimizer(options.optimizer)
 		}
-
-		for _, batch := range batches {
-			network.updateMiniBatch(batch, eta, lmbda, len(inputs))
+		if options.scheduler != nil {
+			eta = options.scheduler.LearningRate(i, eta)
+		}
+		for _, batch := range miniBatches(options.shuffle(inputs, i), miniBatchSize) {
+			select {
+			case <-ctx.Done():
+				network.restore(epochStart)
+				restoreOptimizer(options.optimizer, optimizerStart)
+				return history, ctx.Err()
+			default:
+			}
+			if options.batchCost {
+				history.BatchCost = append(history.BatchCost, network.Cost(batch))
+			}
+			network.updateMiniBatch(batch, eta, lmbda, len(inputs), updates, options)
+			updates++
 		}
 
 		cost := network.Cost(testData)
-		if doingBestOfN {
-			if cost < bestCost {
+		if plateau != nil {
+			plateau.ObserveCost(cost)
+		}
+		if observer, ok := options.scheduler.(CostObserver); ok && len(testData) > 0 {
+			observer.ObserveCost(cost)
+		}
+		if doingBestOfN || options.earlyStopping != nil {
+			if cost < bestCost-options.earlyStopping.minDelta() {
 				bestCost = cost
 				bestNetwork = network.Copy()
 				bestBefore = 0
@@ -182,122 +517,304 @@ func (network NN) Train(inputs []TrainItem, epochs, miniBatchSize int, eta, etaF
 			}
 		}
 
+		trainCost := math.NaN()
+		if !options.skipTrainingCost || options.onEpoch != nil {
+			trainCost = network.Cost(inputs)
+		}
+		if !options.skipTrainingCost {
+			history.TrainingCost = append(history.TrainingCost, trainCost)
+		}
+		valCost, valAccuracy := math.NaN(), math.NaN()
 		if len(testData) > 0 {
-			fmt.Printf("Epoch %d: %f\n", i, network.Evaluate(testData))
+			valCost, valAccuracy = cost, network.Evaluate(testData)
+			history.ValidationCost = append(history.ValidationCost, valCost)
+			history.ValidationAccuracy = append(history.ValidationAccuracy, valAccuracy)
+			options.logf("Epoch %d: %f\n", i, valAccuracy)
 			if printCost {
-				fmt.Printf("Cost: %f\n", cost)
+				options.logf("Cost: %f\n", cost)
 			}
 		} else {
-			fmt.Printf("Epoch %d finished.\n", i)
+			options.logf("Epoch %d finished.\n", i)
+		}
+		if options.onEpoch != nil && !options.onEpoch(i, trainCost, valCost, valAccuracy) {
+			break
 		}
 		i++
+		if options.earlyStopping != nil && bestBefore >= options.earlyStopping.Patience {
+			if options.earlyStopping.RestoreBest {
+				network.restore(bestNetwork)
+			}
+			break
+		}
 	}
+	return
 }
 
-func (network NN) updateMiniBatch(batch []TrainItem, eta, lmbda float64, n int) {
-	var err error
-	cxw := make([]matrices.Matrix, len(network.weights))
-	cxb := make([]matrices.Matrix, len(network.biases))
-	for i, m := range network.weights {
-		cxw[i] = matrices.InitMatrix(m.Rows(), m.Cols())
+// restore sets weights, biases and batch normalization of network to those of other network with the same layers,
+// slices are updated in place so that copies of network see the change
+func (network NN) restore(other NN) {
+	copy(network.weights, other.weights)
+	copy(network.biases, other.biases)
+	for i, norm := range other.batchNorm {
+		if norm != nil {
+			*network.batchNorm[i] = *norm.Copy()
+		}
+	}
+}
+
+// miniBatches partitions items into consecutive batches of given size, last batch may be smaller
+func miniBatches(items []TrainItem, size int) [][]TrainItem {
+	batches := make([][]TrainItem, 0, (len(items)+size-1)/size)
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		batches = append(batches, items[start:end])
 	}
-	for i, m := range network.biases {
-		cxb[i] = matrices.InitMatrix(m.Rows(), m.Cols())
+	return batches
+}
+
+func (network NN) updateMiniBatch(batch []TrainItem, eta, lmbda float64, n, step int, options trainOptions) {
+	var err error
+	cxw, cxb := network.parallelBackprop(batch, options)
+	if network.hasBatchNorm() {
+		x, _ := network.stackBatch(batch)
+		network.updateRunningStatistics(x)
 	}
 
-	for _, item := range batch {
-		nablaW, nablaB := network.backprop(item)
-		for i, nabla := range nablaW {
-			cxw[i], err = cxw[i].Add(nabla)
-			if err != nil {
+	// turn summed gradients into their mean and add gradient of L2 regularization,
+	// gradients are owned by this call so they are updated in place
+	for i := range cxw {
+		cxw[i].ApplyInPlace(matrices.Mult(1 / float64(len(batch))))
+		// scales of batch normalization follow weights and are not regularized
+		if lmbda != 0 && i < len(network.weights) {
+			decay := options.pool.Get(network.weights[i].Rows(), network.weights[i].Cols())
+			if err = decay.AddInPlace(network.weights[i]); err != nil {
 				panic(err)
 			}
-		}
-		for i, nabla := range nablaB {
-			cxb[i], err = cxb[i].Add(nabla)
-			if err != nil {
+			decay.ApplyInPlace(matrices.Mult(lmbda / float64(n)))
+			if err = cxw[i].AddInPlace(decay); err != nil {
 				panic(err)
 			}
+			options.pool.Put(decay)
+		}
+	}
+	for i := range cxb {
+		cxb[i].ApplyInPlace(matrices.Mult(1 / float64(len(batch))))
+	}
+	if options.clipNorm > 0 {
+		clipped := ClipByGlobalNorm(append(cxw, cxb...), options.clipNorm)
+		cxw, cxb = clipped[:len(cxw)], clipped[len(cxw):]
+	}
+	if options.gradientNoise > 0 {
+		sigma := options.gradientNoise / math.Pow(1+float64(step), 0.55)
+		for i := range cxw {
+			addNoise(cxw[i], sigma, options)
+		}
+		for i := range cxb {
+			addNoise(cxb[i], sigma, options)
 		}
 	}
-	multByConst := matrices.Mult(eta / float64(len(batch)))
-	for i, w := range cxw {
-		regularization := matrices.Mult(1 - eta*lmbda/float64(n))
-		reduced := w.Apply(multByConst)
-		network.weights[i], err = network.weights[i].Apply(regularization).Sub(reduced)
+
+	weights, biases := network.parameters()
+	weightUpdates, biasUpdates := options.optimizer.Updates(weights, biases, cxw, cxb, eta)
+	for i, update := range weightUpdates {
+		weights[i], err = weights[i].Add(update)
 		if err != nil {
 			panic(err)
 		}
+		if options.maxNorm > 0 && i < len(network.weights) {
+			weights[i] = weights[i].ClipColumnNorms(options.maxNorm)
+		}
 	}
-	for i, b := range cxb {
-		reduced := b.Apply(multByConst)
-		network.biases[i], err = network.biases[i].Sub(reduced)
+	for i, update := range biasUpdates {
+		biases[i], err = biases[i].Add(update)
 		if err != nil {
 			panic(err)
 		}
 	}
+	network.setParameters(weights, biases)
+	for _, m := range append(cxw, cxb...) {
+		options.pool.Put(m)
+	}
 }
 
-func (network NN) backprop(item TrainItem) ([]matrices.Matrix, []matrices.Matrix) {
-	nablaW := make([]matrices.Matrix, len(network.weights))
-	nablaB := make([]matrices.Matrix, len(network.biases))
-	for i, m := range network.weights {
-		nablaW[i] = matrices.InitMatrix(m.Rows(), m.Cols())
+// addNoise adds gaussian noise with given standard deviation to every element of matrix in place
+func addNoise(m matrices.Matrix, sigma float64, options trainOptions) {
+	m.ApplyInPlace(func(f float64) float64 { return f + sigma*options.normFloat64() })
+}
+
+// backpropBatch returns gradients of cost for weights and biases summed over items of batch,
+// all items are propagated together as rows of single matrix. Dropout is applied with masks drawn from rng
+// unless rng is nil
+func (network NN) backpropBatch(batch []TrainItem, rng *rand.Rand, pool *matrices.MatrixPool) ([]matrices.Matrix, []matrices.Matrix) {
+	x, y := network.stackBatch(batch)
+	nablaW, nablaB, _ := network.backpropRows(x, y, network.rowWeights(batch), rng, pool, true, false)
+	return nablaW, nablaB
+}
+
+// stackBatch returns values and targets of items of batch stacked as rows of matrices
+func (network NN) stackBatch(batch []TrainItem) (matrices.Matrix, matrices.Matrix) {
+	inputs := make([]matrices.Matrix, len(batch))
+	targets := make([]matrices.Matrix, len(batch))
+	for i, item := range batch {
+		target, err := network.target(item)
+		if err != nil {
+			panic(err)
+		}
+		inputs[i] = item.Values
+		targets[i] = target
 	}
-	for i, m := range network.biases {
-		nablaB[i] = matrices.InitMatrix(m.Rows(), m.Cols())
+	x, err := matrices.VStack(inputs...)
+	if err != nil {
+		panic(err)
+	}
+	y, err := matrices.VStack(targets...)
+	if err != nil {
+		panic(err)
 	}
+	return x, y
+}
 
-	activation := item.Values
+// parallelBackprop splits batch into chunks, one for each worker, computes their gradients concurrently
+// and sums them in chunk order. Every chunk gets own random source for dropout seeded from training source.
+// Batch normalization needs statistics of whole batch, so batch is then propagated as single chunk
+func (network NN) parallelBackprop(batch []TrainItem, options trainOptions) ([]matrices.Matrix, []matrices.Matrix) {
+	chunkSize := (len(batch) + options.workers - 1) / options.workers
+	if network.hasBatchNorm() {
+		chunkSize = len(batch)
+	}
+	chunks := miniBatches(batch, chunkSize)
+	rngs := make([]*rand.Rand, len(chunks))
+	if network.hasDropout() {
+		for i := range rngs {
+			rngs[i] = rand.New(rand.NewSource(options.int63()))
+		}
+	}
+	if len(chunks) == 1 {
+		return network.backpropBatch(batch, rngs[0], options.pool)
+	}
+	nablaWs := make([][]matrices.Matrix, len(chunks))
+	nablaBs := make([][]matrices.Matrix, len(chunks))
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []TrainItem) {
+			defer wg.Done()
+			nablaWs[i], nablaBs[i] = network.backpropBatch(chunk, rngs[i], options.pool)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	// gradients of first chunk are owned by this call, so rest is accumulated into them in place
+	nablaW, nablaB := nablaWs[0], nablaBs[0]
+	for k := 1; k < len(chunks); k++ {
+		for i := range nablaW {
+			if err := nablaW[i].AddInPlace(nablaWs[k][i]); err != nil {
+				panic(err)
+			}
+		}
+		for i := range nablaB {
+			if err := nablaB[i].AddInPlace(nablaBs[k][i]); err != nil {
+				panic(err)
+			}
+		}
+		for _, m := range append(nablaWs[k], nablaBs[k]...) {
+			options.pool.Put(m)
+		}
+	}
+	return nablaW, nablaB
+}
+
+// gradients returns gradients of cost for weights, biases and input of the network
+func (network NN) gradients(item TrainItem) ([]matrices.Matrix, []matrices.Matrix, matrices.Matrix) {
+	y, err := network.target(item)
+	if err != nil {
+		panic(err)
+	}
+	return network.backpropRows(item.Values, y, network.rowWeights([]TrainItem{item}), nil, nil, false, true)
+}
+
+// backpropRows returns gradients of cost for weights, biases and inputs where each row of x is one input
+// and same row of y is its target, gradients of weights and biases are summed over rows, each row is weighted
+// by same row of rowWeights unless it is empty.
+// When rng is not nil, hidden activations are dropped out during training with masks drawn from it.
+// Gradients of weights and biases are taken from pool, which may be nil. Batch normalization uses statistics of x
+// when training and running statistics otherwise, gradients of its scales and shifts follow those of weights
+// and biases in order of layers. Gradient of inputs is computed only when withInput is set and is empty otherwise,
+// training does not need it
+func (network NN) backpropRows(x, y, rowWeights matrices.Matrix, rng *rand.Rand, pool *matrices.MatrixPool, training, withInput bool) ([]matrices.Matrix, []matrices.Matrix, matrices.Matrix) {
+	nablaW := make([]matrices.Matrix, len(network.weights))
+	nablaB := make([]matrices.Matrix, len(network.biases))
+	nablaGamma := make([]matrices.Matrix, len(network.weights))
+	nablaBeta := make([]matrices.Matrix, len(network.weights))
+
+	activation := x
 	activations := make([]matrices.Matrix, len(network.weights)+1)
 	activations[0] = activation
 	zs := make([]matrices.Matrix, len(network.weights))
+	// outputs are activations before dropout, derivatives of some activations are computed from them
+	outputs := make([]matrices.Matrix, len(network.weights))
+	masks := make([]matrices.Matrix, len(network.weights))
+	clampMasks := make([]matrices.Matrix, len(network.weights))
+	caches := make([]normCache, len(network.weights))
 
 	for i := range network.weights {
-		weights := network.weights[i]
-		biases := network.biases[i]
-		multiplied, err := activation.Dot(weights)
+		z, err := network.preActivation(i, activation)
 		if err != nil {
 			panic(err)
 		}
-		z, err := multiplied.Add(biases)
-		if err != nil {
-			panic(err)
+		clampMasks[i] = network.clampMask(i, z)
+		if norm := network.norm(i); norm != nil {
+			z, caches[i] = norm.forward(z, training)
 		}
 		zs[i] = z
-		activation = z.Sigmoid()
+		activation = network.activation(i).Apply(z)
+		outputs[i] = activation
+		if rng != nil && i < len(network.dropout) && network.dropout[i] > 0 {
+			masks[i] = dropoutMask(z.Rows(), z.Cols(), network.dropout[i], rng)
+			if activation, err = activation.Mult(masks[i]); err != nil {
+				panic(err)
+			}
+		}
 		activations[i+1] = activation
 	}
 
-	y, err := matrices.OneHotMatrix(1, item.Distinct, 0, int(item.Label))
-	if err != nil {
-		panic(err)
-	}
-
-	// old code with MSE
-	// costDerivative, err := activations[len(activations) - 1].Sub(y)
-	// if err != nil {
-	//     panic(err)
-	// }
-	// delta, err := costDerivative.Mult(zs[len(zs) - 1].SigmoidPrime())
-	// if err != nil {
-	//     panic(err)
-	// }
+	// summing rows of delta gives gradient of biases
+	ones := matrices.Ones(1, x.Rows())
 
-	// new code with cross-entropy
+	// cross-entropy output delta does not depend on output activation prime as it cancels out for sigmoid output,
+	// the same holds for categorical cross-entropy and softmax output
 	delta, err := activations[len(activations)-1].Sub(y)
 	if err != nil {
 		panic(err)
 	}
-	nablaB[len(nablaB)-1] = delta
-	nablaW[len(nablaW)-1], err = activations[len(activations)-2].Transpose().Dot(delta)
-	if err != nil {
-		panic(err)
+	if network.cost == MeanSquaredError {
+		delta, err = delta.Mult(network.prime(len(zs)-1, zs[len(zs)-1], outputs[len(zs)-1]))
+		if err != nil {
+			panic(err)
+		}
+	}
+	if !rowWeights.Empty() {
+		// outer product with row of ones spreads weight of every row over its columns
+		spread, err := rowWeights.Dot(matrices.Ones(1, delta.Cols()))
+		if err != nil {
+			panic(err)
+		}
+		if delta, err = delta.Mult(spread); err != nil {
+			panic(err)
+		}
+	}
+	if mask := clampMasks[len(zs)-1]; !mask.Empty() {
+		if delta, err = delta.Mult(mask); err != nil {
+			panic(err)
+		}
 	}
+	nablaW[len(nablaW)-1], nablaB[len(nablaB)-1] = network.layerGradients(len(nablaW)-1, activations[len(activations)-2], delta, ones, pool)
 
 	for l := 2; l < len(network.layers); l++ {
-		z := zs[len(zs)-l]
-		sp := z.SigmoidPrime()
+		sp := network.prime(len(zs)-l, zs[len(zs)-l], outputs[len(zs)-l])
 		dotted, err := delta.Dot(network.weights[len(network.weights)-l+1].Transpose())
 		if err != nil {
 			panic(err)
@@ -306,70 +823,241 @@ func (network NN) backprop(item TrainItem) ([]matrices.Matrix, []matrices.Matrix
 		if err != nil {
 			panic(err)
 		}
-		nablaB[len(nablaB)-l] = delta
-		nablaW[len(nablaW)-l], err = activations[len(activations)-l-1].Transpose().Dot(delta)
-		if err != nil {
+		if mask := masks[len(zs)-l]; !mask.Empty() {
+			if delta, err = delta.Mult(mask); err != nil {
+				panic(err)
+			}
+		}
+		if norm := network.norm(len(zs) - l); norm != nil {
+			delta, nablaGamma[len(zs)-l], nablaBeta[len(zs)-l] = norm.backward(delta, caches[len(zs)-l], training)
+		}
+		if mask := clampMasks[len(zs)-l]; !mask.Empty() {
+			if delta, err = delta.Mult(mask); err != nil {
+				panic(err)
+			}
+		}
+		nablaW[len(nablaW)-l], nablaB[len(nablaB)-l] = network.layerGradients(len(nablaW)-l, activations[len(activations)-l-1], delta, ones, pool)
+	}
+
+	var nablaX matrices.Matrix
+	if withInput {
+		if nablaX, err = delta.Dot(network.weights[0].Transpose()); err != nil {
 			panic(err)
 		}
 	}
 
+	for i := range network.batchNorm {
+		if network.batchNorm[i] != nil {
+			nablaW = append(nablaW, nablaGamma[i])
+			nablaB = append(nablaB, nablaBeta[i])
+		}
+	}
+	return nablaW, nablaB, nablaX
+}
+
+// layerGradients returns gradients of weights and biases of layer transition i from activation of previous layer
+// and delta of next layer, ones is row of ones summing delta over rows
+func (network NN) layerGradients(i int, activation, delta, ones matrices.Matrix, pool *matrices.MatrixPool) (matrices.Matrix, matrices.Matrix) {
+	nablaW := pool.Get(network.weights[i].Rows(), network.weights[i].Cols())
+	if err := activation.Transpose().DotInto(delta, nablaW); err != nil {
+		panic(err)
+	}
+	nablaB := pool.Get(network.biases[i].Rows(), network.biases[i].Cols())
+	if err := ones.DotInto(delta, nablaB); err != nil {
+		panic(err)
+	}
 	return nablaW, nablaB
 }
 
+// networkFormat identifies serialized networks and networkFormatVersion is version of their layout,
+// it must be increased whenever meaning of serialized fields changes. Version 2 added batch normalization,
+// version 3 class weights, version 4 stores batch normalization as flags of layers and normalizations of enabled ones
+const (
+	networkFormat        = "back-propagation-nn"
+	networkFormatVersion = 4
+)
+
+// exportedNetwork holds serialized fields of network shared by JSON and gob formats
+type exportedNetwork struct {
+	Format       string
+	Version      int
+	Layers       []int
+	Weights      []matrices.Matrix
+	Biases       []matrices.Matrix
+	Clamps       []float64     `json:",omitempty"`
+	Activations  []string      `json:",omitempty"`
+	TargetScaler *TargetScaler `json:",omitempty"`
+	Cost         CostFunction  `json:",omitempty"`
+	CostEpsilon  float64       `json:",omitempty"`
+	Dropout      []float64     `json:",omitempty"`
+	// BatchNorm holds normalizations of layers enabled in BatchNormLayers in order of layers,
+	// before version 4 it held entry for every layer with null for layers without normalization
+	BatchNorm       []BatchNorm `json:",omitempty"`
+	BatchNormLayers []bool      `json:",omitempty"`
+	ClassWeights    []float64   `json:",omitempty"`
+	CompensatedDot  bool        `json:",omitempty"`
+}
+
+func (network NN) export() (exportedNetwork, error) {
+	var activations []string
+	for _, activation := range network.activations {
+		name, err := activationName(activation)
+		if err != nil {
+			return exportedNetwork{}, err
+		}
+		activations = append(activations, name)
+	}
+	var batchNorm []BatchNorm
+	var batchNormLayers []bool
+	for _, norm := range network.batchNorm {
+		batchNormLayers = append(batchNormLayers, norm != nil)
+		if norm != nil {
+			batchNorm = append(batchNorm, *norm)
+		}
+	}
+	return exportedNetwork{
+		Format:          networkFormat,
+		Version:         networkFormatVersion,
+		Layers:          network.layers,
+		Weights:         network.weights,
+		Biases:          network.biases,
+		Clamps:          network.clamps,
+		Activations:     activations,
+		TargetScaler:    network.targetScaler,
+		Cost:            network.cost,
+		CostEpsilon:     network.costEpsilon,
+		Dropout:         network.dropout,
+		BatchNorm:       batchNorm,
+		BatchNormLayers: batchNormLayers,
+		ClassWeights:    network.classWeights,
+		CompensatedDot:  network.compensatedDot,
+	}, nil
+}
+
+// load sets network from its serialized fields, files saved before format was versioned have neither format nor version
+func (network *NN) load(exported exportedNetwork) error {
+	if exported.Format != networkFormat && (exported.Format != "" || exported.Version != 0) {
+		return fmt.Errorf("nn: unknown network format %q", exported.Format)
+	}
+	if exported.Version < 0 || exported.Version > networkFormatVersion {
+		return fmt.Errorf("nn: unsupported network format version %d, newest supported version is %d", exported.Version, networkFormatVersion)
+	}
+	var activations []Activation
+	for _, name := range exported.Activations {
+		activation, err := activationByName(name)
+		if err != nil {
+			return err
+		}
+		activations = append(activations, activation)
+	}
+	batchNorm, err := exported.batchNorm()
+	if err != nil {
+		return err
+	}
+	network.layers = exported.Layers
+	network.weights = exported.Weights
+	network.biases = exported.Biases
+	network.clamps = exported.Clamps
+	network.activations = activations
+	network.targetScaler = exported.TargetScaler
+	network.cost = exported.Cost
+	network.costEpsilon = exported.CostEpsilon
+	network.dropout = exported.Dropout
+	network.batchNorm = batchNorm
+	network.classWeights = exported.ClassWeights
+	network.compensatedDot = exported.CompensatedDot
+	return nil
+}
+
+// batchNorm returns batch normalization of every layer with nil for layers without it
+func (exported exportedNetwork) batchNorm() ([]*BatchNorm, error) {
+	if exported.Version < 4 {
+		// null entries of older versions are decoded as empty normalizations
+		var batchNorm []*BatchNorm
+		for i := range exported.BatchNorm {
+			if exported.BatchNorm[i].Gamma.Empty() {
+				batchNorm = append(batchNorm, nil)
+			} else {
+				batchNorm = append(batchNorm, &exported.BatchNorm[i])
+			}
+		}
+		return batchNorm, nil
+	}
+	var batchNorm []*BatchNorm
+	next := 0
+	for _, enabled := range exported.BatchNormLayers {
+		if !enabled {
+			batchNorm = append(batchNorm, nil)
+			continue
+		}
+		if next >= len(exported.BatchNorm) {
+			return nil, errors.New("nn: fewer batch normalizations than enabled layers")
+		}
+		batchNorm = append(batchNorm, &exported.BatchNorm[next])
+		next++
+	}
+	if next != len(exported.BatchNorm) {
+		return nil, errors.New("nn: more batch normalizations than enabled layers")
+	}
+	return batchNorm, nil
+}
+
 // MarshalJSON implements Marshaler interface
 func (network NN) MarshalJSON() ([]byte, error) {
-	exportedNetwork := struct {
-		Layers  []int
-		Weights []matrices.Matrix
-		Biases  []matrices.Matrix
-	}{
-		network.layers,
-		network.weights,
-		network.biases,
+	exported, err := network.export()
+	if err != nil {
+		return nil, err
 	}
-	return json.Marshal(exportedNetwork)
+	return json.Marshal(exported)
 }
 
 // UnmarshalJSON implements Unmarshaler interface
 func (network *NN) UnmarshalJSON(serialized []byte) error {
-	var exportedNetwork struct {
-		Layers  []int
-		Weights []matrices.Matrix
-		Biases  []matrices.Matrix
-	}
-	if err := json.Unmarshal(serialized, &exportedNetwork); err != nil {
+	var exported exportedNetwork
+	if err := json.Unmarshal(serialized, &exported); err != nil {
 		return err
 	}
-	network.layers = exportedNetwork.Layers
-	network.weights = exportedNetwork.Weights
-	network.biases = exportedNetwork.Biases
-	return nil
+	return network.load(exported)
 }
 
-// Save exports network to file as JSON
-func (network NN) Save(path string) error {
+// WriteTo writes network to w as JSON, it implements WriterTo interface
+func (network NN) WriteTo(w io.Writer) (int64, error) {
 	res, err := json.Marshal(network)
 	if err != nil {
-		return err
+		return 0, err
 	}
+	n, err := w.Write(res)
+	return int64(n), err
+}
+
+// ReadNetwork reads network written by WriteTo from r
+func ReadNetwork(r io.Reader) (NN, error) {
+	var network NN
+	dat, err := ioutil.ReadAll(r)
+	if err != nil {
+		return network, err
+	}
+	err = json.Unmarshal(dat, &network)
+	return network, err
+}
+
+// Save exports network to file as JSON
+func (network NN) Save(path string) error {
 	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	_, err = f.Write(res)
+	_, err = network.WriteTo(f)
 	return err
 }
 
 // LoadNetwork loads network from JSON file
 func LoadNetwork(path string) (NN, error) {
-	var network NN
-	dat, err := ioutil.ReadFile(path)
+	f, err := os.Open(path)
 	if err != nil {
-		return network, err
+		return NN{}, err
 	}
-
-	err = json.Unmarshal(dat, &network)
-
-	return network, err
+	defer f.Close()
+	return ReadNetwork(f)
 }
@@ -0,0 +1,245 @@
+package nn
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// blobs returns n items of given number of classes in two dimensions, items of every class are scattered
+// around its own centre on unit circle
+func blobs(n, classes int, seed int64) []TrainItem {
+	rng := rand.New(rand.NewSource(seed))
+	items := make([]TrainItem, n)
+	for i := range items {
+		class := i % classes
+		angle := 2 * math.Pi * float64(class) / float64(classes)
+		values := []float64{math.Cos(angle) + 0.3*rng.NormFloat64(), math.Sin(angle) + 0.3*rng.NormFloat64()}
+		items[i] = InitTrainItem(values, float64(class), classes)
+	}
+	return items
+}
+
+// equalMatrices fails test unless matrices of both slices are equal within tolerance
+func equalMatrices(t *testing.T, name string, got, want []matrices.Matrix, tolerance float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d matrices, want %d", name, len(got), len(want))
+	}
+	for i := range got {
+		if !got[i].Equals(want[i], tolerance) {
+			t.Errorf("%s[%d] = %v, want %v", name, i, got[i], want[i])
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	network := NewNN([]int{2, 4, 3, 2}, WithSeed(1), WithActivation(ReLU{}, Tanh{}, Sigmoid{}))
+	if err := network.SetBatchNorm([]bool{true, false}); err != nil {
+		t.Fatal(err)
+	}
+	if err := network.SetPreActivationClamp([]float64{5, 5, 5}); err != nil {
+		t.Fatal(err)
+	}
+	copied := network.Copy()
+	equalNetworks(t, copied, network)
+
+	original := network.Copy()
+	if _, err := copied.TrainWithConfig(XORDataset(), TrainConfig{Epochs: 5, MiniBatchSize: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if copied.FeedForward(XORDataset()[0].Values).Equals(network.FeedForward(XORDataset()[0].Values), 1e-12) {
+		t.Error("training of copy did not change its output")
+	}
+	equalNetworks(t, network, original)
+}
+
+func TestMiniBatches(t *testing.T) {
+	for _, tc := range []struct{ n, size, batches int }{
+		{10, 5, 2},
+		{10, 3, 4},
+		{10, 10, 1},
+		{10, 20, 1},
+		{1, 3, 1},
+	} {
+		items := indexedItems(tc.n)
+		batches := miniBatches(items, tc.size)
+		if len(batches) != tc.batches {
+			t.Errorf("%d items in batches of %d: got %d batches, want %d", tc.n, tc.size, len(batches), tc.batches)
+		}
+		next := 0
+		for i, batch := range batches {
+			if i < len(batches)-1 && len(batch) != tc.size {
+				t.Errorf("%d items in batches of %d: batch %d has %d items", tc.n, tc.size, i, len(batch))
+			}
+			for _, item := range batch {
+				if index(item) != next {
+					t.Errorf("%d items in batches of %d: got item %d, want %d", tc.n, tc.size, index(item), next)
+				}
+				next++
+			}
+		}
+		if next != tc.n {
+			t.Errorf("%d items in batches of %d: batches hold %d items", tc.n, tc.size, next)
+		}
+	}
+}
+
+func BenchmarkBackprop(b *testing.B) {
+	network := NewNN([]int{2, 64, 32, 3}, WithSeed(1))
+	batch := blobs(1000, 3, 1)
+	b.Run("per-sample", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			nablaW, nablaB := network.backpropBatch(batch[:1], nil, nil)
+			for _, item := range batch[1:] {
+				itemW, itemB := network.backpropBatch([]TrainItem{item}, nil, nil)
+				for k := range nablaW {
+					if err := nablaW[k].AddInPlace(itemW[k]); err != nil {
+						b.Fatal(err)
+					}
+				}
+				for k := range nablaB {
+					if err := nablaB[k].AddInPlace(itemB[k]); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		}
+	})
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			network.backpropBatch(batch, nil, nil)
+		}
+	})
+}
+
+func TestSeededTrainingSavesIdenticalNetworks(t *testing.T) {
+	save := func(seed int64) []byte {
+		rng := rand.New(rand.NewSource(seed))
+		network := InitNNWithRand([]int{2, 8, 3}, rng, NormalizedInit)
+		if err := network.SetDropout([]float64{0.3}); err != nil {
+			t.Fatal(err)
+		}
+		config := TrainConfig{Epochs: 3, MiniBatchSize: 7, Options: []TrainOption{RandSource(rng)}}
+		if _, err := network.TrainWithConfig(blobs(60, 3, 1), config); err != nil {
+			t.Fatal(err)
+		}
+		var buffer bytes.Buffer
+		if _, err := network.WriteTo(&buffer); err != nil {
+			t.Fatal(err)
+		}
+		return buffer.Bytes()
+	}
+	if !bytes.Equal(save(1), save(1)) {
+		t.Error("training with equal seeds saved different networks")
+	}
+	if bytes.Equal(save(1), save(2)) {
+		t.Error("training with different seeds saved identical networks")
+	}
+}
+
+func BenchmarkUpdateMiniBatch(b *testing.B) {
+	batch := blobs(100, 3, 1)
+	for name, opts := range map[string][]TrainOption{
+		"allocating":       nil,
+		"reusing matrices": {ReuseMatrices()},
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			network := NewNN([]int{2, 64, 32, 3}, WithSeed(1))
+			options := newTrainOptions(opts)
+			for i := 0; i < b.N; i++ {
+				network.updateMiniBatch(batch, 0.1, 0.1, len(batch), i, options)
+			}
+		})
+	}
+}
+
+func TestCompensatedDot(t *testing.T) {
+	// weighted input of single linear neuron cancels catastrophically, exact output is 2
+	input := matrices.InitMatrixWithValues(4, []float64{1e16, 1, -1e16, 1})
+	for _, compensated := range []bool{false, true} {
+		var opts []Option
+		if compensated {
+			opts = append(opts, WithCompensatedDot())
+		}
+		network := NewNN([]int{4, 1}, append(opts, WithActivation(Linear{}))...)
+		if err := network.SetWeights([]matrices.Matrix{matrices.Ones(4, 1)}); err != nil {
+			t.Fatal(err)
+		}
+		if err := network.SetBiases([]matrices.Matrix{matrices.Zeros(1, 1)}); err != nil {
+			t.Fatal(err)
+		}
+		output, err := network.FeedForward(input).At(0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exact := output == 2; exact != compensated {
+			t.Errorf("output with compensated dot %t = %f, want exact output only with compensation", compensated, output)
+		}
+	}
+}
+
+func TestInputGradientOnlyWhenRequested(t *testing.T) {
+	network := NewNN([]int{2, 4, 2}, WithSeed(1))
+	item := blobs(1, 2, 1)[0]
+	y, err := network.target(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, nablaX := network.backpropRows(item.Values, y, matrices.Matrix{}, nil, nil, true, false); !nablaX.Empty() {
+		t.Errorf("training backprop computed input gradient %v", nablaX)
+	}
+	// central differences of cost with respect to every input value
+	const step = 1e-6
+	gradient := network.InputGradient(item)
+	for col := 0; col < item.Values.Cols(); col++ {
+		value, _ := item.Values.At(0, col)
+		shifted := item
+		shifted.Values = item.Values.Copy()
+		shifted.Values.Set(0, col, value+step)
+		plus := network.itemCost(shifted)
+		shifted.Values.Set(0, col, value-step)
+		minus := network.itemCost(shifted)
+		got, _ := gradient.At(0, col)
+		if want := (plus - minus) / (2 * step); math.Abs(got-want) > 1e-6 {
+			t.Errorf("input gradient %d = %g, want %g", col, got, want)
+		}
+	}
+}
+
+func TestClampedUnitsPassNoGradient(t *testing.T) {
+	network := NewNN([]int{2, 4, 2}, WithSeed(1))
+	const c = 0.5
+	if err := network.SetPreActivationClamp([]float64{c, 0}); err != nil {
+		t.Fatal(err)
+	}
+	item := blobs(2, 2, 1)[1]
+	z, err := network.preActivation(0, item.Values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nablaW, nablaB, _ := network.gradients(item)
+	clamped := 0
+	for j := 0; j < z.Cols(); j++ {
+		value, _ := z.At(0, j)
+		if math.Abs(value) < c {
+			continue
+		}
+		clamped++
+		if bias, _ := nablaB[0].At(0, j); bias != 0 {
+			t.Errorf("bias gradient of clamped unit %d = %g, want 0", j, bias)
+		}
+		for i := 0; i < nablaW[0].Rows(); i++ {
+			if weight, _ := nablaW[0].At(i, j); weight != 0 {
+				t.Errorf("weight gradient %d of clamped unit %d = %g, want 0", i, j, weight)
+			}
+		}
+	}
+	if clamped == 0 {
+		t.Fatal("no unit was clamped")
+	}
+}
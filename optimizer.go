@@ -0,0 +1,227 @@
+package nn
+
+import (
+	"math"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// Optimizer turns mean gradients of a mini-batch into updates that are added to weights and biases
+type Optimizer interface {
+	Updates(weights, biases, nablaW, nablaB []matrices.Matrix, eta float64) (weightUpdates, biasUpdates []matrices.Matrix)
+}
+
+// optimizerCopier is implemented by optimizers keeping state between updates, copies of network
+// get their own copy of it
+type optimizerCopier interface {
+	Copy() Optimizer
+}
+
+// copyOptimizer returns copy of stateful optimizer and stateless optimizer itself
+func copyOptimizer(optimizer Optimizer) Optimizer {
+	if copier, ok := optimizer.(optimizerCopier); ok {
+		return copier.Copy()
+	}
+	return optimizer
+}
+
+// restoreOptimizer sets state of optimizer back to that of its copy made by copyOptimizer,
+// optimizers other than those of this package are left unchanged
+func restoreOptimizer(optimizer, saved Optimizer) {
+	switch o := optimizer.(type) {
+	case *MomentumOptimizer:
+		*o = *saved.(*MomentumOptimizer)
+	case *AdamOptimizer:
+		*o = *saved.(*AdamOptimizer)
+	case *RMSPropOptimizer:
+		*o = *saved.(*RMSPropOptimizer)
+	}
+}
+
+// SGD is plain stochastic gradient descent, update is -eta*gradient
+type SGD struct{}
+
+// Updates implements Optimizer interface
+func (SGD) Updates(weights, biases, nablaW, nablaB []matrices.Matrix, eta float64) ([]matrices.Matrix, []matrices.Matrix) {
+	step := func(gradients []matrices.Matrix) []matrices.Matrix {
+		updates := make([]matrices.Matrix, len(gradients))
+		for i, gradient := range gradients {
+			updates[i] = gradient.ScalarMult(-eta)
+		}
+		return updates
+	}
+	return step(nablaW), step(nablaB)
+}
+
+// MomentumOptimizer keeps velocity of every weight and bias, updated as v = mu*v - eta*gradient
+type MomentumOptimizer struct {
+	Mu float64
+
+	vW []matrices.Matrix
+	vB []matrices.Matrix
+}
+
+// NewMomentumOptimizer creates momentum optimizer with given momentum coefficient
+func NewMomentumOptimizer(mu float64) *MomentumOptimizer {
+	return &MomentumOptimizer{Mu: mu}
+}
+
+// Copy creates copy of optimizer including its velocities
+func (momentum *MomentumOptimizer) Copy() Optimizer {
+	return &MomentumOptimizer{Mu: momentum.Mu, vW: copyMatrices(momentum.vW), vB: copyMatrices(momentum.vB)}
+}
+
+// Updates implements Optimizer interface, velocities start from zero again when shapes of parameters change
+func (momentum *MomentumOptimizer) Updates(weights, biases, nablaW, nablaB []matrices.Matrix, eta float64) ([]matrices.Matrix, []matrices.Matrix) {
+	if !sameShapes(momentum.vW, nablaW) || !sameShapes(momentum.vB, nablaB) {
+		momentum.vW, momentum.vB = zerosLike(nablaW), zerosLike(nablaB)
+	}
+	return momentum.step(momentum.vW, nablaW, eta), momentum.step(momentum.vB, nablaB, eta)
+}
+
+func (momentum *MomentumOptimizer) step(v, gradients []matrices.Matrix, eta float64) []matrices.Matrix {
+	updates := make([]matrices.Matrix, len(gradients))
+	for i, gradient := range gradients {
+		var err error
+		v[i], err = v[i].ScalarMult(momentum.Mu).Sub(gradient.ScalarMult(eta))
+		if err != nil {
+			panic(err)
+		}
+		updates[i] = v[i]
+	}
+	return updates
+}
+
+// AdamOptimizer keeps exponentially decaying averages of gradients and their squares
+// and uses their bias-corrected ratio as update direction
+type AdamOptimizer struct {
+	Beta1   float64
+	Beta2   float64
+	Epsilon float64
+
+	t  int
+	mW []matrices.Matrix
+	vW []matrices.Matrix
+	mB []matrices.Matrix
+	vB []matrices.Matrix
+}
+
+// NewAdamOptimizer creates Adam optimizer with commonly used decay rates 0.9 and 0.999
+func NewAdamOptimizer() *AdamOptimizer {
+	return &AdamOptimizer{Beta1: 0.9, Beta2: 0.999, Epsilon: 1e-8}
+}
+
+// Copy creates copy of optimizer including its averages
+func (adam *AdamOptimizer) Copy() Optimizer {
+	return &AdamOptimizer{
+		Beta1:   adam.Beta1,
+		Beta2:   adam.Beta2,
+		Epsilon: adam.Epsilon,
+		t:       adam.t,
+		mW:      copyMatrices(adam.mW),
+		vW:      copyMatrices(adam.vW),
+		mB:      copyMatrices(adam.mB),
+		vB:      copyMatrices(adam.vB),
+	}
+}
+
+// Updates implements Optimizer interface, averages start from zero again when shapes of parameters change
+func (adam *AdamOptimizer) Updates(weights, biases, nablaW, nablaB []matrices.Matrix, eta float64) ([]matrices.Matrix, []matrices.Matrix) {
+	if !sameShapes(adam.mW, nablaW) || !sameShapes(adam.mB, nablaB) {
+		adam.t = 0
+		adam.mW, adam.vW = zerosLike(nablaW), zerosLike(nablaW)
+		adam.mB, adam.vB = zerosLike(nablaB), zerosLike(nablaB)
+	}
+	adam.t++
+	return adam.step(adam.mW, adam.vW, nablaW, eta), adam.step(adam.mB, adam.vB, nablaB, eta)
+}
+
+func (adam *AdamOptimizer) step(m, v, gradients []matrices.Matrix, eta float64) []matrices.Matrix {
+	correction1 := 1 - math.Pow(adam.Beta1, float64(adam.t))
+	correction2 := 1 - math.Pow(adam.Beta2, float64(adam.t))
+	updates := make([]matrices.Matrix, len(gradients))
+	for i, gradient := range gradients {
+		var err error
+		m[i], err = m[i].ScalarMult(adam.Beta1).Add(gradient.ScalarMult(1 - adam.Beta1))
+		if err != nil {
+			panic(err)
+		}
+		v[i], err = v[i].ScalarMult(adam.Beta2).Add(gradient.Apply(matrices.Square).ScalarMult(1 - adam.Beta2))
+		if err != nil {
+			panic(err)
+		}
+		denominator := v[i].ScalarMult(1 / correction2).Apply(math.Sqrt).Apply(matrices.Add(adam.Epsilon))
+		updates[i], err = m[i].ScalarMult(-eta / correction1).Div(denominator)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return updates
+}
+
+// RMSPropOptimizer keeps exponentially decaying average of squared gradients and divides gradients by its root,
+// so step size of every weight adapts to magnitude of its gradients
+type RMSPropOptimizer struct {
+	Decay   float64
+	Epsilon float64
+
+	sW []matrices.Matrix
+	sB []matrices.Matrix
+}
+
+// NewRMSPropOptimizer creates RMSProp optimizer with commonly used decay rate 0.9
+func NewRMSPropOptimizer() *RMSPropOptimizer {
+	return &RMSPropOptimizer{Decay: 0.9, Epsilon: 1e-8}
+}
+
+// Copy creates copy of optimizer including its averages
+func (rmsprop *RMSPropOptimizer) Copy() Optimizer {
+	return &RMSPropOptimizer{Decay: rmsprop.Decay, Epsilon: rmsprop.Epsilon, sW: copyMatrices(rmsprop.sW), sB: copyMatrices(rmsprop.sB)}
+}
+
+// Updates implements Optimizer interface, average starts from zero again when shapes of parameters change
+func (rmsprop *RMSPropOptimizer) Updates(weights, biases, nablaW, nablaB []matrices.Matrix, eta float64) ([]matrices.Matrix, []matrices.Matrix) {
+	if !sameShapes(rmsprop.sW, nablaW) || !sameShapes(rmsprop.sB, nablaB) {
+		rmsprop.sW, rmsprop.sB = zerosLike(nablaW), zerosLike(nablaB)
+	}
+	return rmsprop.step(rmsprop.sW, nablaW, eta), rmsprop.step(rmsprop.sB, nablaB, eta)
+}
+
+func (rmsprop *RMSPropOptimizer) step(s, gradients []matrices.Matrix, eta float64) []matrices.Matrix {
+	updates := make([]matrices.Matrix, len(gradients))
+	for i, gradient := range gradients {
+		var err error
+		s[i], err = s[i].ScalarMult(rmsprop.Decay).Add(gradient.Apply(matrices.Square).ScalarMult(1 - rmsprop.Decay))
+		if err != nil {
+			panic(err)
+		}
+		denominator := s[i].Apply(math.Sqrt).Apply(matrices.Add(rmsprop.Epsilon))
+		updates[i], err = gradient.ScalarMult(-eta).Div(denominator)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return updates
+}
+
+// sameShapes reports whether both slices hold the same number of matrices with equal dimensions
+func sameShapes(mats, others []matrices.Matrix) bool {
+	if len(mats) != len(others) {
+		return false
+	}
+	for i, m := range mats {
+		if m.Rows() != others[i].Rows() || m.Cols() != others[i].Cols() {
+			return false
+		}
+	}
+	return true
+}
+
+// zerosLike returns zero matrices with the same dimensions as given ones
+func zerosLike(mats []matrices.Matrix) []matrices.Matrix {
+	zeros := make([]matrices.Matrix, len(mats))
+	for i, m := range mats {
+		zeros[i] = matrices.InitMatrix(m.Rows(), m.Cols())
+	}
+	return zeros
+}
@@ -0,0 +1,137 @@
+package nn
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+func TestCopyDoesNotShareOptimizerState(t *testing.T) {
+	momentum := NewMomentumOptimizer(0.9)
+	network := NewNN([]int{2, 4, 2}, WithSeed(1), WithOptimizer(momentum))
+	if _, err := network.TrainWithConfig(XORDataset(), TrainConfig{Epochs: 1, MiniBatchSize: 4}); err != nil {
+		t.Fatal(err)
+	}
+	velocity := copyMatrices(momentum.vW)
+	copied := network.Copy()
+	if _, err := copied.TrainWithConfig(XORDataset(), TrainConfig{Epochs: 1, MiniBatchSize: 4}); err != nil {
+		t.Fatal(err)
+	}
+	equalMatrices(t, "velocity", momentum.vW, velocity, 1e-15)
+}
+
+func TestTrainAfterWiden(t *testing.T) {
+	for name, optimizer := range map[string]Optimizer{
+		"momentum": NewMomentumOptimizer(0.9),
+		"adam":     NewAdamOptimizer(),
+		"rmsprop":  NewRMSPropOptimizer(),
+	} {
+		network := NewNN([]int{2, 4, 2}, WithSeed(1), WithOptimizer(optimizer))
+		if _, err := network.TrainWithConfig(XORDataset(), TrainConfig{Epochs: 1, MiniBatchSize: 4}); err != nil {
+			t.Fatal(err)
+		}
+		widened, err := network.Widen(1, 6, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := widened.TrainWithConfig(XORDataset(), TrainConfig{Epochs: 1, MiniBatchSize: 4}); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+// epochsToCost returns number of epochs after which training cost of network falls below target
+func epochsToCost(t *testing.T, optimizer Optimizer, target float64, maxEpochs int) int {
+	t.Helper()
+	network := NewNN([]int{2, 4, 2}, WithSeed(1))
+	epochs := maxEpochs + 1
+	stop := func(epoch int, trainCost, valCost, valAccuracy float64) bool {
+		if trainCost < target {
+			epochs = epoch + 1
+			return false
+		}
+		return true
+	}
+	config := TrainConfig{Epochs: maxEpochs, MiniBatchSize: 4, Eta: 0.5, Options: []TrainOption{UseOptimizer(optimizer), OnEpoch(stop)}}
+	if _, err := network.TrainWithConfig(XORDataset(), config); err != nil {
+		t.Fatal(err)
+	}
+	return epochs
+}
+
+func TestMomentumConvergesFasterThanSGD(t *testing.T) {
+	const target = 0.1
+	sgd := epochsToCost(t, SGD{}, target, 5000)
+	momentum := epochsToCost(t, NewMomentumOptimizer(0.9), target, 5000)
+	if momentum >= sgd {
+		t.Errorf("momentum needed %d epochs and SGD %d to reach cost %g", momentum, sgd, target)
+	}
+}
+
+func TestRMSPropUpdatesDoNotDependOnGradientScale(t *testing.T) {
+	// gradients of first layer are million times larger than those of second
+	nablaW := []matrices.Matrix{matrices.Full(2, 2, 1000), matrices.Full(2, 2, -1e-3)}
+	nablaB := []matrices.Matrix{matrices.Full(1, 2, 1000), matrices.Full(1, 2, -1e-3)}
+	rmsprop := NewRMSPropOptimizer()
+	const eta = 0.01
+	for step := 0; step < 20; step++ {
+		weightUpdates, biasUpdates := rmsprop.Updates(nil, nil, nablaW, nablaB, eta)
+		for _, updates := range [][]matrices.Matrix{weightUpdates, biasUpdates} {
+			large, err := updates[0].Max()
+			if err != nil {
+				t.Fatal(err)
+			}
+			small, err := updates[1].Max()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// update of every weight is at most eta/sqrt(1-Decay) and does not depend on scale of its gradient
+			// up to Epsilon added to root of average
+			if math.Abs(large+small) > 1e-3*math.Abs(large) || math.Abs(large) > eta/math.Sqrt(1-rmsprop.Decay)+1e-12 {
+				t.Fatalf("step %d: updates of layers are %g and %g", step, large, small)
+			}
+		}
+	}
+}
+
+// cancelAfter is context cancelled once Done has been called given number of times
+type cancelAfter struct {
+	context.Context
+	cancel context.CancelFunc
+	calls  int
+}
+
+func (ctx *cancelAfter) Done() <-chan struct{} {
+	if ctx.calls--; ctx.calls < 0 {
+		ctx.cancel()
+	}
+	return ctx.Context.Done()
+}
+
+func TestCancelRestoresOptimizerState(t *testing.T) {
+	adam := NewAdamOptimizer()
+	network := NewNN([]int{2, 4, 2}, WithSeed(1), WithOptimizer(adam))
+	var weights, moments []matrices.Matrix
+	var steps int
+	keepFirstEpoch := OnEpoch(func(epoch int, trainCost, valCost, valAccuracy float64) bool {
+		if epoch == 0 {
+			weights, moments, steps = copyMatrices(network.weights), copyMatrices(adam.mW), adam.t
+		}
+		return true
+	})
+	// Done is called once before every epoch and once before every mini-batch, so training
+	// is cancelled in the middle of second epoch
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := network.TrainContext(&cancelAfter{Context: ctx, cancel: cancel, calls: 7}, XORDataset(), 5, 1, 0.1, 0, 0, nil, keepFirstEpoch)
+	if err != context.Canceled {
+		t.Fatalf("err = %v, want %v", err, context.Canceled)
+	}
+	equalMatrices(t, "weights", network.weights, weights, 1e-15)
+	equalMatrices(t, "first moments", adam.mW, moments, 1e-15)
+	if adam.t != steps {
+		t.Errorf("adam.t = %d, want %d", adam.t, steps)
+	}
+}
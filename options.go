@@ -0,0 +1,239 @@
+package nn
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// TrainOption configures optional behavior of Train
+type TrainOption func(*trainOptions)
+
+type trainOptions struct {
+	maxNorm       float64
+	gradientNoise float64
+	rand          *rand.Rand
+	batchCost     bool
+	shuffleSeed   *int64
+	optimizer     Optimizer
+	workers       int
+	earlyStopping *EarlyStopping
+	scheduler     Scheduler
+	logger        Logger
+	clipNorm      float64
+	pool          *matrices.MatrixPool
+	onEpoch       EpochCallback
+	// skipTrainingCost leaves out cost on training inputs unless callback needs it
+	skipTrainingCost bool
+}
+
+// EpochCallback is called after every epoch with costs and accuracy of that epoch,
+// validation values are NaN without test data. Returning false stops training
+type EpochCallback func(epoch int, trainCost, valCost, valAccuracy float64) bool
+
+// Logger receives progress messages of training, *log.Logger satisfies it
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// writerLogger writes progress messages to underlying writer
+type writerLogger struct {
+	io.Writer
+}
+
+func (logger writerLogger) Printf(format string, v ...interface{}) {
+	fmt.Fprintf(logger.Writer, format, v...)
+}
+
+// EarlyStopping stops training when cost on test data has not improved by more than MinDelta for Patience epochs
+type EarlyStopping struct {
+	Patience int
+	MinDelta float64
+	// RestoreBest restores weights of epoch with the lowest test cost when training stops
+	RestoreBest bool
+}
+
+func (earlyStopping *EarlyStopping) minDelta() float64 {
+	if earlyStopping == nil {
+		return 0
+	}
+	return earlyStopping.MinDelta
+}
+
+func newTrainOptions(opts []TrainOption) trainOptions {
+	options := trainOptions{optimizer: SGD{}, workers: 1}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// MaxNorm constrains L2 norm of incoming weights of every neuron to given cap after each update, 0 disables it
+func MaxNorm(c float64) TrainOption {
+	return func(options *trainOptions) {
+		options.maxNorm = c
+	}
+}
+
+// GradientNoise adds gaussian noise with standard deviation sigma/(1+t)^0.55 to gradients at update t, 0 disables it
+func GradientNoise(sigma float64) TrainOption {
+	return func(options *trainOptions) {
+		options.gradientNoise = sigma
+	}
+}
+
+// RandSource sets random source used during training, global source of math/rand is used by default
+func RandSource(r *rand.Rand) TrainOption {
+	return func(options *trainOptions) {
+		options.rand = r
+	}
+}
+
+// RecordBatchCost records cost of every mini-batch into History returned by Train
+func RecordBatchCost() TrainOption {
+	return func(options *trainOptions) {
+		options.batchCost = true
+	}
+}
+
+// RecordTrainingCost records cost on training inputs after every epoch into History returned by Train,
+// which leaves it out by default because computing it takes a pass over all inputs. Other training functions
+// always record it
+func RecordTrainingCost() TrainOption {
+	return func(options *trainOptions) {
+		options.skipTrainingCost = false
+	}
+}
+
+// skipTrainingCost leaves cost on training inputs out of History
+func skipTrainingCost() TrainOption {
+	return func(options *trainOptions) {
+		options.skipTrainingCost = true
+	}
+}
+
+// ShuffleSeed makes shuffle of every epoch derived only from baseSeed+epoch, so order of given epoch
+// is reproducible regardless of epochs trained before it
+func ShuffleSeed(baseSeed int64) TrainOption {
+	return func(options *trainOptions) {
+		options.shuffleSeed = &baseSeed
+	}
+}
+
+// UseOptimizer sets optimizer that turns gradients into updates, plain SGD is used by default.
+// Optimizer keeps its state between calls, so it should not be shared by concurrent trainings
+func UseOptimizer(optimizer Optimizer) TrainOption {
+	return func(options *trainOptions) {
+		options.optimizer = optimizer
+	}
+}
+
+// Workers sets number of goroutines computing gradients of each mini-batch, single one is used by default
+// and up to runtime.NumCPU() speed up large mini-batches. Mini-batch is split into the same chunks for given
+// number of workers, so results are deterministic for it, but differ by rounding between numbers of workers.
+// Networks with batch normalization propagate every mini-batch as whole
+func Workers(n int) TrainOption {
+	return func(options *trainOptions) {
+		if n < 1 {
+			n = 1
+		}
+		options.workers = n
+	}
+}
+
+// EarlyStop stops training according to given configuration, training without test data is rejected
+func EarlyStop(earlyStopping EarlyStopping) TrainOption {
+	return func(options *trainOptions) {
+		options.earlyStopping = &earlyStopping
+	}
+}
+
+// LearningRateSchedule sets scheduler that adjusts learning rate at start of every epoch, scheduler implementing
+// CostObserver also gets cost on test data after every epoch
+func LearningRateSchedule(scheduler Scheduler) TrainOption {
+	return func(options *trainOptions) {
+		options.scheduler = scheduler
+	}
+}
+
+// LogTo redirects progress messages of training to given logger, nil logger silences them
+func LogTo(logger Logger) TrainOption {
+	return func(options *trainOptions) {
+		options.logger = logger
+	}
+}
+
+// LogToWriter redirects progress messages of training to given writer
+func LogToWriter(w io.Writer) TrainOption {
+	return LogTo(writerLogger{w})
+}
+
+// ClipGradientNorm scales mean gradients of each mini-batch down when their global L2 norm exceeds maxNorm,
+// 0 disables clipping
+func ClipGradientNorm(maxNorm float64) TrainOption {
+	return func(options *trainOptions) {
+		options.clipNorm = maxNorm
+	}
+}
+
+// OnEpoch sets callback called after every epoch of training
+func OnEpoch(callback EpochCallback) TrainOption {
+	return func(options *trainOptions) {
+		options.onEpoch = callback
+	}
+}
+
+// ReuseMatrices recycles gradient and temporary matrices of mini-batch updates through a matrix pool
+// to reduce allocations. Gradients passed to optimizer are reused after its Updates returns,
+// so custom optimizers must not keep references to them
+func ReuseMatrices() TrainOption {
+	return func(options *trainOptions) {
+		options.pool = matrices.NewMatrixPool()
+	}
+}
+
+func (options trainOptions) logf(format string, v ...interface{}) {
+	if options.logger != nil {
+		options.logger.Printf(format, v...)
+	}
+}
+
+func (options trainOptions) normFloat64() float64 {
+	if options.rand == nil {
+		return rand.NormFloat64()
+	}
+	return options.rand.NormFloat64()
+}
+
+func (options trainOptions) int63() int64 {
+	if options.rand == nil {
+		return rand.Int63()
+	}
+	return options.rand.Int63()
+}
+
+func (options trainOptions) perm(n int) []int {
+	if options.rand == nil {
+		return rand.Perm(n)
+	}
+	return options.rand.Perm(n)
+}
+
+// epochPerm returns permutation used to shuffle inputs in given epoch
+func (options trainOptions) epochPerm(epoch, n int) []int {
+	if options.shuffleSeed != nil {
+		return rand.New(rand.NewSource(*options.shuffleSeed + int64(epoch))).Perm(n)
+	}
+	return options.perm(n)
+}
+
+// shuffle returns inputs in random order of given epoch
+func (options trainOptions) shuffle(inputs []TrainItem, epoch int) []TrainItem {
+	shuffled := make([]TrainItem, len(inputs))
+	for i, v := range options.epochPerm(epoch, len(inputs)) {
+		shuffled[i] = inputs[v]
+	}
+	return shuffled
+}
@@ -0,0 +1,101 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// indexedItems returns n items whose only value is their index
+func indexedItems(n int) []TrainItem {
+	items := make([]TrainItem, n)
+	for i := range items {
+		items[i] = InitTrainItem([]float64{float64(i)}, 0, 1)
+	}
+	return items
+}
+
+// index returns index of item created by indexedItems
+func index(item TrainItem) int {
+	value, err := item.Values.At(0, 0)
+	if err != nil {
+		panic(err)
+	}
+	return int(value)
+}
+
+func TestShuffleKeepsEveryItemOnce(t *testing.T) {
+	inputs := indexedItems(50)
+	for name, options := range map[string]trainOptions{
+		"rand source":  newTrainOptions([]TrainOption{RandSource(rand.New(rand.NewSource(1)))}),
+		"shuffle seed": newTrainOptions([]TrainOption{ShuffleSeed(1)}),
+	} {
+		for epoch := 0; epoch < 5; epoch++ {
+			seen := make([]int, len(inputs))
+			for _, item := range options.shuffle(inputs, epoch) {
+				seen[index(item)]++
+			}
+			for i, count := range seen {
+				if count != 1 {
+					t.Errorf("%s, epoch %d: item %d appears %d times", name, epoch, i, count)
+				}
+			}
+		}
+	}
+}
+
+func TestShuffleIsUniform(t *testing.T) {
+	const n, runs = 5, 50000
+	inputs := indexedItems(n)
+	for name, options := range map[string]trainOptions{
+		"rand source":  newTrainOptions([]TrainOption{RandSource(rand.New(rand.NewSource(1)))}),
+		"shuffle seed": newTrainOptions([]TrainOption{ShuffleSeed(1)}),
+	} {
+		// counts[i][j] is number of runs where item i ended at position j
+		var counts [n][n]int
+		for epoch := 0; epoch < runs; epoch++ {
+			for position, item := range options.shuffle(inputs, epoch) {
+				counts[index(item)][position]++
+			}
+		}
+		// chi-squared statistic over 25 cells stays below 60 with probability far above 0.9999 for uniform shuffle
+		expected := float64(runs) / n
+		chi2 := 0.0
+		for i := range counts {
+			for _, count := range counts[i] {
+				chi2 += (float64(count) - expected) * (float64(count) - expected) / expected
+			}
+		}
+		if chi2 > 60 {
+			t.Errorf("%s: positions of items are not uniform, chi-squared %f, counts %v", name, chi2, counts)
+		}
+	}
+}
+
+func TestTrainRecordsTrainingCostOnlyOnRequest(t *testing.T) {
+	inputs := XORDataset()
+	network := NewNN([]int{2, 3, 2}, WithSeed(1))
+	if history := network.Copy().Train(inputs, 3, 2, 0.5, 0, 0, nil, false); history.TrainingCost != nil {
+		t.Errorf("Train recorded training cost %v without RecordTrainingCost", history.TrainingCost)
+	}
+	var reported []float64
+	network.Copy().Train(inputs, 3, 2, 0.5, 0, 0, nil, false, OnEpoch(func(epoch int, trainCost, valCost, valAccuracy float64) bool {
+		reported = append(reported, trainCost)
+		return true
+	}))
+	recorded := network.Copy().Train(inputs, 3, 2, 0.5, 0, 0, nil, false, RecordTrainingCost()).TrainingCost
+	withConfig, err := network.Copy().TrainWithConfig(inputs, TrainConfig{Epochs: 3, MiniBatchSize: 2, Eta: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, costs := range map[string][]float64{"OnEpoch": reported, "RecordTrainingCost": recorded, "TrainWithConfig": withConfig.TrainingCost} {
+		if len(costs) != 3 {
+			t.Fatalf("%s: got %d training costs, want 3", name, len(costs))
+		}
+		for i, cost := range costs {
+			if math.IsNaN(cost) {
+				t.Errorf("%s: training cost of epoch %d is NaN", name, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package nn
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestParallelBackpropMatchesSerial(t *testing.T) {
+	network := NewNN([]int{2, 16, 8, 3}, WithSeed(1))
+	batch := blobs(100, 3, 1)
+	serialW, serialB := network.parallelBackprop(batch, newTrainOptions([]TrainOption{Workers(1)}))
+	for _, workers := range []int{2, 3, 7, 100} {
+		parallelW, parallelB := network.parallelBackprop(batch, newTrainOptions([]TrainOption{Workers(workers)}))
+		equalMatrices(t, fmt.Sprintf("weights with %d workers", workers), parallelW, serialW, 1e-12)
+		equalMatrices(t, fmt.Sprintf("biases with %d workers", workers), parallelB, serialB, 1e-12)
+	}
+}
+
+func TestParallelTrainingIsDeterministic(t *testing.T) {
+	train := func() []byte {
+		network := NewNN([]int{2, 16, 3}, WithSeed(1))
+		if err := network.SetDropout([]float64{0.2}); err != nil {
+			t.Fatal(err)
+		}
+		options := []TrainOption{Workers(4), RandSource(rand.New(rand.NewSource(1)))}
+		if _, err := network.TrainWithConfig(blobs(100, 3, 1), TrainConfig{Epochs: 3, Options: options}); err != nil {
+			t.Fatal(err)
+		}
+		serialized, err := json.Marshal(network)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return serialized
+	}
+	if !bytes.Equal(train(), train()) {
+		t.Error("training with equal seeds and number of workers gave different networks")
+	}
+}
+
+func BenchmarkParallelBackprop(b *testing.B) {
+	network := NewNN([]int{2, 128, 64, 3}, WithSeed(1))
+	batch := blobs(1000, 3, 1)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			options := newTrainOptions([]TrainOption{Workers(workers)})
+			for i := 0; i < b.N; i++ {
+				network.parallelBackprop(batch, options)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package nn
+
+import "github.com/tek-shinobi/back-propagation-nn/matrices"
+
+// Standardize computes per-feature mean and standard deviation of values of items, standardizes values
+// of items with them and returns them, so the same transform can be applied to test data by ApplyStandardization.
+// Features with zero variance get standard deviation 1
+func Standardize(items []TrainItem) (mean, std matrices.Matrix) {
+	if len(items) == 0 {
+		return
+	}
+	values := make([]matrices.Matrix, len(items))
+	for i, item := range items {
+		values[i] = item.Values
+	}
+	scaler, err := FitTargetScaler(values)
+	if err != nil {
+		panic(err)
+	}
+	ApplyStandardization(items, scaler.Mean, scaler.Std)
+	return scaler.Mean, scaler.Std
+}
+
+// ApplyStandardization replaces values of items by (values - mean) / std
+func ApplyStandardization(items []TrainItem, mean, std matrices.Matrix) {
+	scaler := TargetScaler{mean, std}
+	for i := range items {
+		standardized, err := scaler.Transform(items[i].Values)
+		if err != nil {
+			panic(err)
+		}
+		items[i].Values = standardized
+	}
+}
+
+// Normalize computes per-feature minimum and maximum of values of items, rescales values of items
+// into [0,1] with them and returns them, so the same transform can be applied to test data by ApplyNormalization
+func Normalize(items []TrainItem) (min, max matrices.Matrix) {
+	if len(items) == 0 {
+		return
+	}
+	min, max = items[0].Values.Copy(), items[0].Values.Copy()
+	for _, item := range items[1:] {
+		var err error
+		if min, err = min.MinElem(item.Values); err != nil {
+			panic(err)
+		}
+		if max, err = max.MaxElem(item.Values); err != nil {
+			panic(err)
+		}
+	}
+	ApplyNormalization(items, min, max)
+	return min, max
+}
+
+// ApplyNormalization replaces values of items by (values - min) / (max - min),
+// features with equal minimum and maximum are only shifted by min
+func ApplyNormalization(items []TrainItem, min, max matrices.Matrix) {
+	scale, err := max.Sub(min)
+	if err != nil {
+		panic(err)
+	}
+	scale = scale.Apply(func(f float64) float64 {
+		if f == 0 {
+			return 1
+		}
+		return f
+	})
+	ApplyStandardization(items, min, scale)
+}
@@ -0,0 +1,109 @@
+package nn
+
+import (
+	"errors"
+	"math"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// TargetScaler standardizes regression targets per column and maps network outputs back to original units
+type TargetScaler struct {
+	Mean matrices.Matrix
+	Std  matrices.Matrix
+}
+
+// FitTargetScaler computes per-column mean and standard deviation of given 1×n targets,
+// columns with zero variance get standard deviation 1
+func FitTargetScaler(targets []matrices.Matrix) (TargetScaler, error) {
+	if len(targets) == 0 {
+		return TargetScaler{}, errors.New("nn: cannot fit target scaler without targets")
+	}
+	cols := targets[0].Cols()
+	mean := matrices.InitMatrix(1, cols)
+	for _, target := range targets {
+		if target.Rows() != 1 || target.Cols() != cols {
+			return TargetScaler{}, errors.New("nn: all targets must be row vectors of the same width")
+		}
+		var err error
+		if mean, err = mean.Add(target); err != nil {
+			return TargetScaler{}, err
+		}
+	}
+	mean = mean.ScalarMult(1 / float64(len(targets)))
+
+	variance := matrices.InitMatrix(1, cols)
+	for _, target := range targets {
+		diff, err := target.Sub(mean)
+		if err != nil {
+			return TargetScaler{}, err
+		}
+		squared, err := diff.Mult(diff)
+		if err != nil {
+			return TargetScaler{}, err
+		}
+		if variance, err = variance.Add(squared); err != nil {
+			return TargetScaler{}, err
+		}
+	}
+	std := variance.ScalarMult(1 / float64(len(targets))).Apply(func(f float64) float64 {
+		if f == 0 {
+			return 1
+		}
+		return math.Sqrt(f)
+	})
+	return TargetScaler{mean, std}, nil
+}
+
+// Transform standardizes target into units the network is trained on
+func (scaler TargetScaler) Transform(target matrices.Matrix) (matrices.Matrix, error) {
+	centered, err := target.Sub(scaler.Mean)
+	if err != nil {
+		return centered, err
+	}
+	return centered.Div(scaler.Std)
+}
+
+// Inverse maps standardized network output back to original units
+func (scaler TargetScaler) Inverse(output matrices.Matrix) (matrices.Matrix, error) {
+	scaled, err := output.Mult(scaler.Std)
+	if err != nil {
+		return scaled, err
+	}
+	return scaled.Add(scaler.Mean)
+}
+
+// Copy creates copy of given scaler
+func (scaler TargetScaler) Copy() TargetScaler {
+	return TargetScaler{scaler.Mean.Copy(), scaler.Std.Copy()}
+}
+
+// SetTargetScaler stores scaler in network, training then standardizes item targets with it
+// and PredictValues, EvaluateRegression and ServeHTTP map outputs back to original units
+func (network *NN) SetTargetScaler(scaler TargetScaler) {
+	copied := scaler.Copy()
+	network.targetScaler = &copied
+}
+
+// target returns target of item the network is trained on, targets of regression items are standardized
+// when network has target scaler
+func (network NN) target(item TrainItem) (matrices.Matrix, error) {
+	y, err := item.target()
+	if err != nil || network.targetScaler == nil || item.Target.Empty() {
+		return y, err
+	}
+	return network.targetScaler.Transform(y)
+}
+
+// PredictValues returns network output for regression, mapped back to original units when network has target scaler
+func (network NN) PredictValues(input matrices.Matrix) matrices.Matrix {
+	output := network.FeedForward(input)
+	if network.targetScaler == nil {
+		return output
+	}
+	values, err := network.targetScaler.Inverse(output)
+	if err != nil {
+		panic(err)
+	}
+	return values
+}
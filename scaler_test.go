@@ -0,0 +1,81 @@
+package nn
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// lineItems returns n regression items of y = 1000 + 500x with x drawn uniformly from [-1, 1]
+func lineItems(n int, seed int64) []TrainItem {
+	rng := rand.New(rand.NewSource(seed))
+	items := make([]TrainItem, n)
+	for i := range items {
+		x := 2*rng.Float64() - 1
+		items[i] = InitRegressionItem([]float64{x}, []float64{1000 + 500*x})
+	}
+	return items
+}
+
+// scaledNetwork returns regression network with target scaler fitted to targets of items
+func scaledNetwork(items []TrainItem) NN {
+	targets := make([]matrices.Matrix, len(items))
+	for i, item := range items {
+		targets[i] = item.Target
+	}
+	scaler, err := FitTargetScaler(targets)
+	if err != nil {
+		panic(err)
+	}
+	network := NewNN([]int{1, 8, 1}, WithSeed(1), WithActivation(Tanh{}, Linear{}))
+	network.SetCostFunction(MeanSquaredError)
+	network.SetTargetScaler(scaler)
+	return network
+}
+
+func TestTrainingUsesTargetScaler(t *testing.T) {
+	inputs, testData := lineItems(200, 1), lineItems(50, 2)
+	network := scaledNetwork(inputs)
+	if _, err := network.TrainWithConfig(inputs, TrainConfig{Epochs: 50, MiniBatchSize: 10, Eta: 0.05}); err != nil {
+		t.Fatal(err)
+	}
+	mse, r2 := network.EvaluateRegression(testData)
+	if r2 < 0.99 {
+		t.Errorf("EvaluateRegression = %g, %g, want R² of at least 0.99", mse, r2)
+	}
+}
+
+func TestEvaluateRegressionInOriginalUnits(t *testing.T) {
+	items := lineItems(20, 1)
+	network := scaledNetwork(items)
+	for i := range items {
+		items[i].Target = network.PredictValues(items[i].Values)
+	}
+	if mse, _ := network.EvaluateRegression(items); mse > 1e-20 {
+		t.Errorf("mse against own predictions = %g, want 0", mse)
+	}
+	if cost := network.Cost(items); cost > 1e-20 {
+		t.Errorf("cost against own predictions = %g, want 0", cost)
+	}
+}
+
+func TestServeHTTPReturnsValuesInOriginalUnits(t *testing.T) {
+	network := scaledNetwork(lineItems(20, 1))
+	request := httptest.NewRequest(http.MethodPost, "/predict", strings.NewReader(`{"values": [0.5]}`))
+	recorder := httptest.NewRecorder()
+	network.ServeHTTP(recorder, request)
+	var response PredictResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := network.PredictValues(matrices.InitMatrixWithValues(1, []float64{0.5})).At(0, 0)
+	if len(response.Values) != 1 || math.Abs(response.Values[0]-want) > 1e-9 {
+		t.Errorf("Values = %v, want [%g]", response.Values, want)
+	}
+}
@@ -0,0 +1,104 @@
+package nn
+
+import "math"
+
+// Scheduler decides learning rate used in each epoch, Train queries it at start of every epoch
+type Scheduler interface {
+	LearningRate(epoch int, currentEta float64) float64
+}
+
+// StepDecay multiplies learning rate by Factor every Step epochs, Factor 0.5 halves it
+type StepDecay struct {
+	Step   int
+	Factor float64
+}
+
+// LearningRate implements Scheduler interface
+func (decay StepDecay) LearningRate(epoch int, currentEta float64) float64 {
+	if epoch > 0 && decay.Step > 0 && epoch%decay.Step == 0 {
+		return currentEta * decay.Factor
+	}
+	return currentEta
+}
+
+// ExponentialDecay multiplies learning rate by Rate every epoch
+type ExponentialDecay struct {
+	Rate float64
+}
+
+// LearningRate implements Scheduler interface
+func (decay ExponentialDecay) LearningRate(epoch int, currentEta float64) float64 {
+	if epoch > 0 {
+		return currentEta * decay.Rate
+	}
+	return currentEta
+}
+
+// CosineAnnealing decreases learning rate from rate of first epoch to MinEta along half cosine
+// over Period epochs and then starts again
+type CosineAnnealing struct {
+	Period int
+	MinEta float64
+
+	maxEta float64
+}
+
+// NewCosineAnnealing creates cosine annealing schedule with given period and minimal learning rate
+func NewCosineAnnealing(period int, minEta float64) *CosineAnnealing {
+	return &CosineAnnealing{Period: period, MinEta: minEta}
+}
+
+// LearningRate implements Scheduler interface
+func (annealing *CosineAnnealing) LearningRate(epoch int, currentEta float64) float64 {
+	if epoch == 0 || annealing.maxEta == 0 {
+		annealing.maxEta = currentEta
+	}
+	if annealing.Period <= 0 {
+		return annealing.maxEta
+	}
+	progress := float64(epoch%annealing.Period) / float64(annealing.Period)
+	return annealing.MinEta + (annealing.maxEta-annealing.MinEta)*(1+math.Cos(math.Pi*progress))/2
+}
+
+// CostObserver is implemented by schedulers adapting learning rate to cost on test data,
+// Train reports cost of every epoch to them when it has test data
+type CostObserver interface {
+	ObserveCost(cost float64)
+}
+
+// ReduceOnPlateau multiplies learning rate by Factor when cost on test data has not improved by more than
+// MinDelta for Patience epochs, as long as learning rate is above MinEta. Best-of-N training halves
+// learning rate this way
+type ReduceOnPlateau struct {
+	Patience int
+	Factor   float64
+	MinDelta float64
+	MinEta   float64
+
+	best     float64
+	observed bool
+	waiting  int
+}
+
+// NewReduceOnPlateau creates plateau schedule with given patience, factor and minimal learning rate
+func NewReduceOnPlateau(patience int, factor, minEta float64) *ReduceOnPlateau {
+	return &ReduceOnPlateau{Patience: patience, Factor: factor, MinEta: minEta}
+}
+
+// ObserveCost implements CostObserver interface
+func (plateau *ReduceOnPlateau) ObserveCost(cost float64) {
+	if !plateau.observed || cost < plateau.best-plateau.MinDelta {
+		plateau.best, plateau.observed, plateau.waiting = cost, true, 0
+		return
+	}
+	plateau.waiting++
+}
+
+// LearningRate implements Scheduler interface
+func (plateau *ReduceOnPlateau) LearningRate(epoch int, currentEta float64) float64 {
+	if plateau.waiting < plateau.Patience || !(currentEta > plateau.MinEta) {
+		return currentEta
+	}
+	plateau.waiting = 0
+	return currentEta * plateau.Factor
+}
@@ -0,0 +1,95 @@
+package nn
+
+import "testing"
+
+func TestReduceOnPlateau(t *testing.T) {
+	plateau := NewReduceOnPlateau(2, 0.5, 0.2)
+	eta := 1.0
+	// learning rate is halved after two epochs without improvement by more than MinDelta, but not below MinEta
+	plateau.MinDelta = 0.01
+	costs := []float64{1, 0.9, 0.95, 0.9, 0.895, 0.9, 0.8, 0.9, 0.9, 0.9, 0.9, 0.9, 0.9}
+	want := []float64{1, 1, 1, 1, 0.5, 0.5, 0.25, 0.25, 0.25, 0.125, 0.125, 0.125, 0.125}
+	for epoch, cost := range costs {
+		if eta = plateau.LearningRate(epoch, eta); eta != want[epoch] {
+			t.Errorf("epoch %d: learning rate %g, want %g", epoch, eta, want[epoch])
+		}
+		plateau.ObserveCost(cost)
+	}
+}
+
+// bestOfNEtas returns learning rates of epochs of best-of-N training with given validation costs
+// using halving rule of best-of-N as it was before it was expressed by ReduceOnPlateau
+func bestOfNEtas(costs []float64, epochs int, eta, etaFraction float64) []float64 {
+	oldEta, bestCost, bestBefore := eta, costs[0], 0
+	var etas []float64
+	for _, cost := range costs[1:] {
+		if bestBefore >= epochs {
+			if !(etaFraction > 0 && eta*etaFraction > oldEta) {
+				break
+			}
+			bestBefore = 0
+			eta /= 2
+		}
+		etas = append(etas, eta)
+		if cost < bestCost {
+			bestCost, bestBefore = cost, 0
+		} else {
+			bestBefore++
+		}
+	}
+	return etas
+}
+
+func TestReduceOnPlateauReproducesBestOfN(t *testing.T) {
+	// first cost is cost before training
+	costs := []float64{2, 1.5, 1.6, 1.4, 1.5, 1.5, 1.5, 1.3, 1.4, 1.4, 1.4, 1.4, 1.4, 1.4, 1.4, 1.4, 1.4, 1.4}
+	for _, etaFraction := range []float64{0, 1, 2, 3, 8} {
+		want := bestOfNEtas(costs, 2, 0.5, etaFraction)
+		plateau := &ReduceOnPlateau{Patience: 2, Factor: 0.5, MinEta: 0.5 / etaFraction}
+		plateau.ObserveCost(costs[0])
+		eta := 0.5
+		var got []float64
+		for epoch, cost := range costs[1:] {
+			if plateau.waiting >= plateau.Patience {
+				reduced := plateau.LearningRate(epoch, eta)
+				if etaFraction <= 0 || reduced == eta {
+					break
+				}
+				eta = reduced
+			}
+			got = append(got, eta)
+			plateau.ObserveCost(cost)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("fraction %g: got learning rates %v, want %v", etaFraction, got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("fraction %g: got learning rates %v, want %v", etaFraction, got, want)
+			}
+		}
+	}
+}
+
+func TestBestOfNTrainsLongerWithEtaFraction(t *testing.T) {
+	// quarter of test items is mislabeled, so test cost soon stops improving
+	test := blobs(30, 3, 2)
+	for i := range test {
+		if i%4 == 0 {
+			test[i].Label = float64((int(test[i].Label) + 1) % 3)
+		}
+	}
+	epochs := func(etaFraction float64) int {
+		network := NewNN([]int{2, 8, 3}, WithSeed(1))
+		config := TrainConfig{Epochs: 3, BestOfN: true, EtaFraction: etaFraction, Eta: 3, TestData: test, Options: []TrainOption{ShuffleSeed(1)}}
+		history, err := network.TrainWithConfig(blobs(90, 3, 1), config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(history.ValidationCost)
+	}
+	without, with := epochs(0), epochs(8)
+	if with <= without {
+		t.Errorf("best-of-N trained %d epochs with eta fraction 8 and %d without it", with, without)
+	}
+}
@@ -0,0 +1,69 @@
+package nn
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// PredictRequest is body of prediction request served by ServeHTTP
+type PredictRequest struct {
+	Values []float64 `json:"values"`
+}
+
+// PredictResponse is body of response to prediction request
+type PredictResponse struct {
+	Class int `json:"class"`
+	// Probabilities holds outputs of sigmoid and softmax output layers normalized to sum to one
+	Probabilities []float64 `json:"probabilities,omitempty"`
+	// Values holds outputs of other output layers and of network with target scaler, in original units
+	Values []float64 `json:"values,omitempty"`
+}
+
+// maxRequestSize is the largest body of prediction request ServeHTTP reads
+const maxRequestSize = 1 << 20
+
+// ServeHTTP implements http.Handler, so loaded network can be served directly, e.g.
+// http.Handle("/predict", network). It accepts POST of PredictRequest JSON with one value per input neuron
+// and responds with PredictResponse JSON holding index of largest output as class and either probabilities
+// of all classes or regression values.
+// Network is only read during prediction, so concurrent requests are safe as long as it is not trained meanwhile
+func (network NN) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var request PredictRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("malformed request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(request.Values) != network.layers[0] {
+		http.Error(w, fmt.Sprintf("expected %d values, got %d", network.layers[0], len(request.Values)), http.StatusBadRequest)
+		return
+	}
+	input := matrices.InitMatrixWithValues(len(request.Values), request.Values)
+	var response PredictResponse
+	if network.targetScaler == nil && isProbabilistic(network.activation(len(network.weights)-1)) {
+		class, probabilities := network.Predict(input)
+		response = PredictResponse{Class: class, Probabilities: probabilities.To2D()[0]}
+	} else {
+		values := network.PredictValues(input)
+		class, err := values.MaxAt()
+		if err != nil {
+			panic(err)
+		}
+		response = PredictResponse{Class: class, Values: values.To2D()[0]}
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot encode response: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	// error means client has gone away and response cannot be delivered anyway
+	_, _ = w.Write(body)
+}
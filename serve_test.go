@@ -0,0 +1,68 @@
+package nn
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tek-shinobi/back-propagation-nn/matrices"
+)
+
+// serve posts body to network and returns recorded response
+func serve(network NN, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	network.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/predict", strings.NewReader(body)))
+	return recorder
+}
+
+// linearNetwork returns network of one input and outputs weight*input with linear activation
+func linearNetwork(weights ...float64) NN {
+	network := NewNN([]int{1, len(weights)}, WithActivation(Linear{}))
+	network.weights[0] = matrices.InitMatrixWithValues(len(weights), weights)
+	network.biases[0] = matrices.InitMatrix(1, len(weights))
+	return network
+}
+
+func TestServeHTTPRegressionValues(t *testing.T) {
+	recorder := serve(linearNetwork(1, -1), `{"values": [1]}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %q", recorder.Code, recorder.Body)
+	}
+	var response PredictResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Class != 0 || response.Probabilities != nil || len(response.Values) != 2 || response.Values[0] != 1 || response.Values[1] != -1 {
+		t.Errorf("response = %+v, want class 0 and values [1 -1] without probabilities", response)
+	}
+}
+
+func TestServeHTTPProbabilities(t *testing.T) {
+	recorder := serve(NewNN([]int{2, 3, 2}, WithSeed(1)), `{"values": [0.5, -0.5]}`)
+	var response PredictResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Probabilities) != 2 || math.Abs(response.Probabilities[0]+response.Probabilities[1]-1) > 1e-12 || response.Values != nil {
+		t.Errorf("response = %+v, want probabilities summing to one without values", response)
+	}
+}
+
+func TestServeHTTPErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		network NN
+		body    string
+		status  int
+	}{
+		"infinite output": {linearNetwork(math.MaxFloat64), `{"values": [10]}`, http.StatusInternalServerError},
+		"too large body":  {linearNetwork(1), `{"values": [` + strings.Repeat(" ", maxRequestSize) + `1]}`, http.StatusBadRequest},
+		"wrong width":     {linearNetwork(1), `{"values": [1, 2]}`, http.StatusBadRequest},
+	} {
+		if recorder := serve(tc.network, tc.body); recorder.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d", name, recorder.Code, tc.status)
+		}
+	}
+}
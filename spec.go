@@ -0,0 +1,59 @@
+package nn
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Spec describes network architecture, e.g. {"layers":[784,128,10],"hidden":"relu","output":"sigmoid","init":"he"}
+type Spec struct {
+	Layers []int  `json:"layers"`
+	Hidden string `json:"hidden"`
+	Output string `json:"output"`
+	Init   string `json:"init"`
+}
+
+// InitNNFromSpec creates new randomly initialized network described by JSON spec,
+// hidden and output activations default to sigmoid. Softmax output is paired with CategoricalCrossEntropy cost
+func InitNNFromSpec(serialized []byte) (NN, error) {
+	var spec Spec
+	if err := json.Unmarshal(serialized, &spec); err != nil {
+		return NN{}, err
+	}
+	if len(spec.Layers) < 2 {
+		return NN{}, errors.New("nn: spec must have at least input and output layer")
+	}
+	for _, layer := range spec.Layers {
+		if layer <= 0 {
+			return NN{}, errors.New("nn: spec layers must have positive size")
+		}
+	}
+	initializer, err := initializerByName(spec.Init)
+	if err != nil {
+		return NN{}, err
+	}
+	hidden, err := specActivation(spec.Hidden)
+	if err != nil {
+		return NN{}, err
+	}
+	output, err := specActivation(spec.Output)
+	if err != nil {
+		return NN{}, err
+	}
+	activations := make([]Activation, len(spec.Layers)-1)
+	for i := range activations {
+		activations[i] = hidden
+	}
+	activations[len(activations)-1] = output
+	if err := checkSoftmax(activations); err != nil {
+		return NN{}, err
+	}
+	return InitNNWithInitializer(spec.Layers, initializer, activations...), nil
+}
+
+func specActivation(name string) (Activation, error) {
+	if name == "" {
+		return Sigmoid{}, nil
+	}
+	return activationByName(name)
+}
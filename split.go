@@ -0,0 +1,52 @@
+package nn
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// TrainTestSplit shuffles copy of items deterministically by seed and splits it into train and test part,
+// test part holds testFraction of items rounded to nearest count, fraction is clamped into [0,1].
+// NaN fraction is invalid and panics
+func TrainTestSplit(items []TrainItem, testFraction float64, seed int64) (train, test []TrainItem) {
+	shuffled := make([]TrainItem, len(items))
+	for i, j := range rand.New(rand.NewSource(seed)).Perm(len(items)) {
+		shuffled[i] = items[j]
+	}
+	testCount := splitCount(len(items), testFraction)
+	// capacity of test part is limited so that appending to it does not overwrite train part
+	return shuffled[testCount:], shuffled[:testCount:testCount]
+}
+
+// StratifiedSplit splits items like TrainTestSplit, but separately for every class given by Label,
+// so both parts keep class proportions of items
+func StratifiedSplit(items []TrainItem, testFraction float64, seed int64) (train, test []TrainItem) {
+	var labels []float64
+	classes := make(map[float64][]TrainItem)
+	for _, item := range items {
+		if _, ok := classes[item.Label]; !ok {
+			labels = append(labels, item.Label)
+		}
+		classes[item.Label] = append(classes[item.Label], item)
+	}
+	r := rand.New(rand.NewSource(seed))
+	for _, label := range labels {
+		classTrain, classTest := TrainTestSplit(classes[label], testFraction, r.Int63())
+		train = append(train, classTrain...)
+		test = append(test, classTest...)
+	}
+	// shuffle again so that classes are not grouped together
+	r.Shuffle(len(train), func(i, j int) { train[i], train[j] = train[j], train[i] })
+	r.Shuffle(len(test), func(i, j int) { test[i], test[j] = test[j], test[i] })
+	return train, test
+}
+
+// splitCount returns number of test items among n items for given test fraction
+func splitCount(n int, testFraction float64) int {
+	if math.IsNaN(testFraction) {
+		panic(errors.New("nn: test fraction must not be NaN"))
+	}
+	testFraction = math.Max(0, math.Min(1, testFraction))
+	return int(math.Round(testFraction * float64(n)))
+}
@@ -0,0 +1,93 @@
+package nn
+
+import (
+	"math"
+	"testing"
+)
+
+// indices returns set of indexes of items created by indexedItems
+func indices(items []TrainItem) map[int]bool {
+	set := make(map[int]bool)
+	for _, item := range items {
+		set[index(item)] = true
+	}
+	return set
+}
+
+func TestTrainTestSplit(t *testing.T) {
+	items := indexedItems(10)
+	for _, tc := range []struct {
+		fraction float64
+		test     int
+	}{
+		{0.3, 3},
+		{0.25, 3},
+		{0, 0},
+		{1, 10},
+		{-0.5, 0},
+		{1.5, 10},
+	} {
+		train, test := TrainTestSplit(items, tc.fraction, 1)
+		if len(test) != tc.test || len(train) != len(items)-tc.test {
+			t.Errorf("fraction %g: got %d train and %d test items, want %d test items", tc.fraction, len(train), len(test), tc.test)
+		}
+		together := indices(append(append([]TrainItem(nil), train...), test...))
+		if len(together) != len(items) {
+			t.Errorf("fraction %g: parts hold %d distinct items, want %d", tc.fraction, len(together), len(items))
+		}
+	}
+	first, _ := TrainTestSplit(items, 0.3, 1)
+	again, _ := TrainTestSplit(items, 0.3, 1)
+	for i := range first {
+		if index(first[i]) != index(again[i]) {
+			t.Fatal("splits with equal seed differ")
+		}
+	}
+}
+
+func TestStratifiedSplitKeepsProportions(t *testing.T) {
+	// 40 items of class 0 and 20 of class 1
+	items := make([]TrainItem, 60)
+	for i := range items {
+		items[i] = InitTrainItem([]float64{float64(i)}, float64(i%3/2), 2)
+	}
+	train, test := StratifiedSplit(items, 0.25, 1)
+	counts := func(items []TrainItem) [2]int {
+		var counts [2]int
+		for _, item := range items {
+			counts[int(item.Label)]++
+		}
+		return counts
+	}
+	if got, want := counts(test), [2]int{10, 5}; got != want {
+		t.Errorf("test part holds %v items of classes, want %v", got, want)
+	}
+	if got, want := counts(train), [2]int{30, 15}; got != want {
+		t.Errorf("train part holds %v items of classes, want %v", got, want)
+	}
+}
+
+func TestSplitNaNFractionPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NaN test fraction did not panic")
+		}
+	}()
+	TrainTestSplit(indexedItems(10), math.NaN(), 1)
+}
+
+func TestAppendToTestKeepsTrain(t *testing.T) {
+	items := indexedItems(10)
+	extra := InitTrainItem([]float64{99}, 0, 1)
+	for name, split := range map[string]func([]TrainItem, float64, int64) ([]TrainItem, []TrainItem){
+		"TrainTestSplit":  TrainTestSplit,
+		"StratifiedSplit": StratifiedSplit,
+	} {
+		train, test := split(items, 0.3, 1)
+		first := index(train[0])
+		test = append(test, extra)
+		if got := index(train[0]); got != first {
+			t.Errorf("%s: appending to test changed train[0] from %d to %d", name, first, got)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package nn
+
+import "errors"
+
+var errEmptyStream = errors.New("nn: stream has no items")
+
+// CostStream returns average cost of items pulled from next until it reports no more items,
+// so dataset does not have to be held in memory
+func (network NN) CostStream(next func() (TrainItem, bool)) (float64, error) {
+	cost := 0.0
+	count := 0
+	for item, ok := next(); ok; item, ok = next() {
+		cost += network.itemCost(item)
+		count++
+	}
+	if count == 0 {
+		return 0, errEmptyStream
+	}
+	return cost / float64(count), nil
+}
+
+// EvaluateStream returns ratio of correctly classified items pulled from next until it reports no more items
+func (network NN) EvaluateStream(next func() (TrainItem, bool)) (float64, error) {
+	correct := 0
+	count := 0
+	for item, ok := next(); ok; item, ok = next() {
+		if network.classifies(item) {
+			correct++
+		}
+		count++
+	}
+	if count == 0 {
+		return 0, errEmptyStream
+	}
+	return float64(correct) / float64(count), nil
+}
@@ -2,15 +2,42 @@ package nn
 
 import "github.com/tek-shinobi/back-propagation-nn/matrices"
 
-// TrainItem represents one item for training of neural network
+// TrainItem represents one item for training of neural network. Classification items carry Label of one of
+// Distinct classes, regression and multi-label items carry Target vector instead, which is used in place of one-hot Label
 type TrainItem struct {
 	Values   matrices.Matrix
 	Label    float64
 	Distinct int
+	Target   matrices.Matrix
 }
 
 // InitTrainItem initializes new training item - values and label
 func InitTrainItem(values []float64, label float64, distinct int) TrainItem {
 	matrix := matrices.InitMatrixWithValues(len(values), values)
-	return TrainItem{matrix, label, distinct}
+	return TrainItem{Values: matrix, Label: label, Distinct: distinct}
+}
+
+// InitRegressionItem initializes new regression training item - values and target vector,
+// network trained on such items should use MeanSquaredError cost and usually Linear output activation
+func InitRegressionItem(values, target []float64) TrainItem {
+	return TrainItem{
+		Values:   matrices.InitMatrixWithValues(len(values), values),
+		Distinct: len(target),
+		Target:   matrices.InitMatrixWithValues(len(target), target),
+	}
+}
+
+// InitMultiLabelItem initializes new multi-label training item - values and target vector holding probability
+// of every class, which is 1 or 0 for hard labels and anything in [0,1] for soft labels. Network trained on such
+// items should keep sigmoid output activation and CrossEntropy cost, which is then summed over classes
+func InitMultiLabelItem(values, labels []float64) TrainItem {
+	return InitRegressionItem(values, labels)
+}
+
+// target returns expected output of network for item
+func (item TrainItem) target() (matrices.Matrix, error) {
+	if !item.Target.Empty() {
+		return item.Target, nil
+	}
+	return matrices.OneHotMatrix(1, item.Distinct, 0, int(item.Label))
 }
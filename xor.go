@@ -0,0 +1,39 @@
+package nn
+
+import (
+	"errors"
+	"math/rand"
+)
+
+const (
+	xorSeed    = 1
+	xorMaxCost = 0.05
+)
+
+// XORDataset returns four training items of XOR function with two distinct classes
+func XORDataset() []TrainItem {
+	return []TrainItem{
+		InitTrainItem([]float64{0, 0}, 0, 2),
+		InitTrainItem([]float64{0, 1}, 1, 2),
+		InitTrainItem([]float64{1, 0}, 1, 2),
+		InitTrainItem([]float64{1, 1}, 0, 2),
+	}
+}
+
+// FitXOR trains small 2-4-2 network on XOR dataset with fixed seed and returns it,
+// error is returned when trained network does not classify all items correctly with near-zero cost
+func FitXOR() (NN, error) {
+	rng := rand.New(rand.NewSource(xorSeed))
+	network := InitNNWithRand([]int{2, 4, 2}, rng, NormalizedInit)
+
+	dataset := XORDataset()
+	options := newTrainOptions([]TrainOption{RandSource(rng), Workers(1)})
+	for epoch := 0; epoch < 2000; epoch++ {
+		network.updateMiniBatch(dataset, 2.0, 0, len(dataset), epoch, options)
+	}
+
+	if network.Evaluate(dataset) < 1 || network.Cost(dataset) > xorMaxCost {
+		return network, errors.New("nn: network did not learn XOR")
+	}
+	return network, nil
+}
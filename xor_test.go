@@ -0,0 +1,17 @@
+package nn
+
+import "testing"
+
+func TestFitXOR(t *testing.T) {
+	network, err := FitXOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataset := XORDataset()
+	if accuracy := network.Evaluate(dataset); accuracy != 1 {
+		t.Errorf("accuracy = %g, want 1", accuracy)
+	}
+	if cost := network.Cost(dataset); cost >= xorMaxCost {
+		t.Errorf("cost = %g, want below %g", cost, xorMaxCost)
+	}
+}